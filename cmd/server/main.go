@@ -2,52 +2,156 @@
 package main
 
 import (
+	"crypto/tls"
 	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"path/filepath"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 
 	"github.com/Chinzzii/leader-replication-go/internal/api"
 	"github.com/Chinzzii/leader-replication-go/internal/cluster"
+	"github.com/Chinzzii/leader-replication-go/internal/consensus"
+	"github.com/Chinzzii/leader-replication-go/internal/hlc"
+	"github.com/Chinzzii/leader-replication-go/internal/repl"
+	"github.com/Chinzzii/leader-replication-go/internal/repl/pb"
 	"github.com/Chinzzii/leader-replication-go/internal/store"
+	"github.com/Chinzzii/leader-replication-go/internal/wal"
 )
 
 func main() {
 	// --- Configuration via command-line flags ---
 	var (
-		id    = flag.String("id", "node-1", "node id")
-		role  = flag.String("role", "leader", "leader|follower")
-		mode  = flag.String("mode", "sync", "sync|async (leader only)")
-		port  = flag.Int("port", 8080, "http port")
-		peers = flag.String("peers", "", "comma-separated peer baseURLs (followers for leader)")
+		id            = flag.String("id", "node-1", "node id")
+		writeConcern  = flag.String("write-concern", "majority", "default /put write concern: 1, majority, all, or an explicit N (leader only)")
+		port          = flag.Int("port", 8080, "http port")
+		peers         = flag.String("peers", "", "comma-separated peer baseURLs (other nodes in the cluster)")
+		dataDir       = flag.String("data-dir", "data", "directory for persistent per-node state")
+		transport     = flag.String("transport", "http", "http|grpc: wire format used to replicate to followers")
+		grpcPort      = flag.Int("grpc-port", 9090, "port the gRPC replication service listens on (-transport=grpc only)")
+		tlsCert       = flag.String("tls-cert", "", "cluster mTLS certificate (PEM); required with -tls-key/-tls-ca for -transport=grpc")
+		tlsKey        = flag.String("tls-key", "", "cluster mTLS private key (PEM)")
+		tlsCA         = flag.String("tls-ca", "", "CA used to verify peer certificates (PEM)")
+		maxClockDrift = flag.Duration("max-clock-drift", hlc.DefaultMaxDrift, "reject a replicated write whose HLC physical time is ahead of this node's wall clock by more than this")
 	)
 	flag.Parse()
 
+	wc, err := cluster.ParseWriteConcern(*writeConcern)
+	if err != nil {
+		log.Fatalf("invalid -write-concern: %v", err)
+	}
+
 	// --- Build Node Configuration ---
+	// Role is no longer set by flag: every node starts as a Raft follower
+	// and leadership is decided by election (see internal/consensus).
 	cfg := &cluster.NodeConfig{
-		ID:    *id,
-		Role:  cluster.Role(*role),
-		Mode:  cluster.Mode(*mode),
-		Port:  *port,
-		Peers: cluster.NormalizePeers(*peers), // Parse the CSV string
+		ID:           *id,
+		WriteConcern: wc,
+		Port:         *port,
+		Peers:        cluster.NormalizePeers(*peers), // Parse the CSV string
 		// BlockPeers map is initialized empty by default.
 		BlockPeers: map[string]bool{},
 	}
 
 	// --- Initialize Dependencies ---
-	kv := store.New()
 	// Create a standard logger
 	logger := log.New(os.Stdout, fmt.Sprintf("[%s] ", cfg.ID), log.LstdFlags)
 
+	writeLog, err := wal.Open(filepath.Join(*dataDir, cfg.ID+"-wal"))
+	if err != nil {
+		logger.Fatalf("opening write-ahead log: %v", err)
+	}
+	writeLog.StartCompactionLoop(5*time.Minute, logger.Printf)
+
+	kv, err := store.New(writeLog)
+	if err != nil {
+		logger.Fatalf("replaying write-ahead log: %v", err)
+	}
+
+	// server is assigned below, after construction; the Apply closure
+	// only runs once an entry actually commits, well after that, so
+	// capturing the not-yet-assigned variable is safe.
+	var server *api.Server
+
+	raftNode, err := consensus.New(consensus.Config{
+		NodeID:    cfg.ID,
+		Self:      cfg.BaseURL(),
+		Peers:     cfg.Peers,
+		Client:    &http.Client{Timeout: 2 * time.Second},
+		Logger:    logger,
+		StatePath: filepath.Join(*dataDir, cfg.ID+".raft.json"),
+		Apply: func(cmd repl.ReplicateRequest) {
+			if err := server.ApplyReplicated(cmd); err != nil {
+				logger.Printf("raft: applying committed entry (lsn=%d): %v", cmd.LSN, err)
+			}
+		},
+	})
+	if err != nil {
+		logger.Fatalf("starting consensus module: %v", err)
+	}
+	raftNode.Start()
+
+	tlsConfig, err := repl.LoadMTLSConfig(*tlsCert, *tlsKey, *tlsCA)
+	if err != nil {
+		logger.Fatalf("loading cluster mTLS config: %v", err)
+	}
+
+	// --- Pick the replication wire format ---
+	var replTransport repl.Transport
+	switch *transport {
+	case "grpc":
+		replTransport = repl.NewGRPCTransport(tlsConfig)
+	case "http":
+		replTransport = repl.NewHTTPTransport(&http.Client{Timeout: 5 * time.Second})
+	default:
+		logger.Fatalf("unknown -transport %q (want http or grpc)", *transport)
+	}
+
+	clock := hlc.New(*maxClockDrift)
+
 	// Pass all dependencies to the server constructor
-	server := api.NewServer(cfg, kv, logger)
+	server = api.NewServer(cfg, kv, raftNode, writeLog, replTransport, clock, logger)
+	server.StartLeaseLoop()
+	server.StartCatchUpLoop()
+
+	// gRPC needs a separate listener: it speaks HTTP/2 natively and isn't
+	// something http.ServeMux can multiplex onto the client-facing port.
+	if *transport == "grpc" {
+		grpcServer := newGRPCServer(tlsConfig)
+		pb.RegisterReplicationServer(grpcServer, &repl.GRPCReplicationServer{Apply: server.ApplyReplicated})
+		lis, err := net.Listen("tcp", fmt.Sprintf(":%d", *grpcPort))
+		if err != nil {
+			logger.Fatalf("listening on gRPC port %d: %v", *grpcPort, err)
+		}
+		go func() {
+			logger.Printf("starting gRPC replication service on :%d", *grpcPort)
+			if err := grpcServer.Serve(lis); err != nil {
+				logger.Fatalf("gRPC server stopped: %v", err)
+			}
+		}()
+	}
 
 	// --- Start Server ---
 	addr := fmt.Sprintf(":%d", cfg.Port)
-	logger.Printf("starting %s %s on %s mode=%s peers=%v", cfg.Role, cfg.ID, addr, cfg.Mode, cfg.Peers)
+	logger.Printf("starting %s on %s write-concern=%s peers=%v", cfg.ID, addr, cfg.WriteConcern, cfg.Peers)
 
 	if err := http.ListenAndServe(addr, server.Routes()); err != nil {
 		logger.Fatal(err)
 	}
 }
+
+// newGRPCServer builds the grpc.Server that receives replicated writes
+// from the leader. tlsConfig is nil unless -tls-cert/-tls-key/-tls-ca
+// were all supplied, in which case peers are authenticated with mTLS.
+func newGRPCServer(tlsConfig *tls.Config) *grpc.Server {
+	if tlsConfig == nil {
+		return grpc.NewServer()
+	}
+	return grpc.NewServer(grpc.Creds(credentials.NewTLS(tlsConfig)))
+}
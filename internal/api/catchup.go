@@ -0,0 +1,56 @@
+// internal/api/catchup.go
+package api
+
+import (
+	"time"
+
+	"github.com/Chinzzii/leader-replication-go/internal/repl"
+)
+
+// catchUpInterval is how often a follower asks the current leader for
+// any writes past its own LastLSN, so a node that was partitioned or
+// just restarted converges on its own instead of depending entirely on
+// the leader's per-peer retry queue to redeliver what it missed.
+const catchUpInterval = 2 * time.Second
+
+// StartCatchUpLoop launches the background loop that periodically calls
+// FetchSince against the current leader's /replicate/since endpoint and
+// applies whatever comes back. It runs until the returned stop function
+// is called.
+func (s *Server) StartCatchUpLoop() (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(catchUpInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.catchUp()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// catchUp is a no-op while this node is the leader or while no leader is
+// known yet. Otherwise it fetches every write past this node's own
+// LastLSN from the leader and applies each one via ApplyReplicated as it
+// streams in, which is idempotent so re-fetching something this node
+// already has (e.g. from the direct push racing this same loop) is
+// harmless.
+func (s *Server) catchUp() {
+	if s.raft.IsLeader() {
+		return
+	}
+	leader := s.raft.LeaderID()
+	if leader == "" {
+		return
+	}
+
+	lsn := s.wal.LastLSN()
+	if err := repl.FetchSince(s.httpClient, leader, lsn, s.ApplyReplicated); err != nil {
+		s.log.Printf("catch-up: fetching since lsn=%d from %s failed: %v", lsn, leader, err)
+	}
+}
@@ -2,26 +2,43 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/Chinzzii/leader-replication-go/internal/cluster"
+	"github.com/Chinzzii/leader-replication-go/internal/consensus"
+	"github.com/Chinzzii/leader-replication-go/internal/hlc"
 	"github.com/Chinzzii/leader-replication-go/internal/repl"
 	"github.com/Chinzzii/leader-replication-go/internal/store"
+	"github.com/Chinzzii/leader-replication-go/internal/wal"
 
 	"github.com/google/uuid" // Used for generating request IDs
 )
 
 // Server holds all dependencies for the HTTP API.
 type Server struct {
-	cfg    *cluster.NodeConfig // This node's configuration
-	store  *store.KV           // The in-memory data store
-	client *http.Client        // HTTP client for replicating to followers
-	log    *log.Logger         // Structured logger
-	mu     sync.Mutex          // Protects stateful operations (e.g., BlockPeers)
+	cfg          *cluster.NodeConfig // This node's configuration
+	store        *store.KV           // The in-memory data store
+	raft         *consensus.Raft     // Leader election / log replication
+	wal          *wal.WAL            // Write-ahead log for crash recovery
+	transport    repl.Transport      // Wire format used to replicate to followers
+	clock        *hlc.Clock          // Hybrid logical clock stamping/ordering writes
+	writeTimeout time.Duration       // Deadline for satisfying a /put's write concern
+	httpClient   *http.Client        // Used for leader-proxied reads and pushing lease grants
+	log          *log.Logger         // Structured logger
+	mu           sync.Mutex          // Protects stateful operations (e.g., BlockPeers)
+
+	peersMu sync.Mutex            // Protects peers below
+	peers   map[string]*peerState // Per-peer replication lag + retry queue, built lazily
+
+	leaseMu sync.Mutex  // Protects lease below
+	lease   *LeaseGrant // Most recent lease this node holds from the current leader, if any
 }
 
 // PutRequest is the JSON body for a client's write request.
@@ -32,31 +49,53 @@ type PutRequest struct {
 
 // PutResponse is the JSON response to a client's write request.
 type PutResponse struct {
-	Status string `json:"status"` // "ok"
-	Mode   string `json:"mode"`   // "sync" or "async"
-	ReqID  string `json:"req_id"` // Unique ID for this request
+	Status       string `json:"status"`        // "ok"
+	WriteConcern string `json:"write_concern"` // the concern that was requested, e.g. "majority"
+	Acks         int    `json:"acks"`          // follower acks observed before responding
+	ReqID        string `json:"req_id"`        // Unique ID for this request
+}
+
+// PeerLag reports how far behind a single follower's acknowledged LSN is
+// from this node's own WAL, for operators to spot a struggling follower.
+type PeerLag struct {
+	Peer         string `json:"peer"`
+	LastAckedLSN uint64 `json:"last_acked_lsn"`
+	Lag          uint64 `json:"lag"`
 }
 
 // Status is the JSON response for the /status endpoint.
 type Status struct {
-	ID      string                 `json:"id"`
-	Role    string                 `json:"role"`
-	Mode    string                 `json:"mode"`
-	Port    int                    `json:"port"`
-	Peers   []string               `json:"peers"`
-	Data    map[string]store.Entry `json:"data"`    // A snapshot of the store
-	Blocked map[string]bool        `json:"blocked"` // List of blocked peers
+	ID           string                 `json:"id"`
+	Role         string                 `json:"role"`
+	WriteConcern string                 `json:"write_concern"`
+	Port         int                    `json:"port"`
+	Peers        []string               `json:"peers"`
+	PeerLag      []PeerLag              `json:"peer_lag,omitempty"` // populated while this node is leading
+	Data         map[string]store.Entry `json:"data"`               // A snapshot of the store
+	Blocked      map[string]bool        `json:"blocked"`            // List of blocked peers
 }
 
-// NewServer creates a new API server instance.
-func NewServer(cfg *cluster.NodeConfig, kv *store.KV, logger *log.Logger) *Server {
+// defaultWriteTimeout bounds how long /put waits for its write concern to
+// be satisfied before responding with whatever acks it has so far;
+// replication to any peer still outstanding continues in the background.
+const defaultWriteTimeout = 2 * time.Second
+
+// NewServer creates a new API server instance. transport picks the wire
+// format used to replicate writes to followers (HTTP/JSON or gRPC); see
+// -transport in cmd/server/main.go. clock is this node's Hybrid Logical
+// Clock, shared between stamping local writes and folding in remote ones.
+func NewServer(cfg *cluster.NodeConfig, kv *store.KV, raft *consensus.Raft, w *wal.WAL, transport repl.Transport, clock *hlc.Clock, logger *log.Logger) *Server {
 	return &Server{
-		cfg:   cfg,
-		store: kv, // Assign the key-value store
-		client: &http.Client{ // Create a client for replication
-			Timeout: 5 * time.Second, // Always set timeouts!
-		},
-		log: logger, // Assign the logger
+		cfg:          cfg,
+		store:        kv,        // Assign the key-value store
+		raft:         raft,      // Assign the consensus module (leader election + log)
+		wal:          w,         // Assign the write-ahead log
+		transport:    transport, // Assign the replication wire format
+		clock:        clock,
+		writeTimeout: defaultWriteTimeout,
+		httpClient:   &http.Client{Timeout: 3 * time.Second},
+		log:          logger, // Assign the logger
+		peers:        map[string]*peerState{},
 		// mu is usable as its zero-value
 	}
 }
@@ -70,7 +109,14 @@ func (s *Server) Routes() http.Handler {
 	mux.HandleFunc("/get", s.handleGet) // Read a value (leader or follower)
 
 	// Internal cluster endpoint
-	mux.HandleFunc("/replicate", s.handleReplicate) // Receive replicated data (follower only)
+	mux.HandleFunc("/replicate", s.handleReplicate)            // Receive replicated data (follower only)
+	mux.HandleFunc("/replicate/since", s.handleReplicateSince) // Catch-up endpoint for lagging followers
+	mux.HandleFunc("/lease", s.handleLease)                    // Receive a read lease grant/revocation from the leader
+
+	// Raft consensus endpoints (leader election + log replication)
+	mux.HandleFunc("/raft/vote", s.handleRaftVote)
+	mux.HandleFunc("/raft/append", s.handleRaftAppend)
+	mux.HandleFunc("/raft/status", s.handleRaftStatus)
 
 	// Admin/status endpoints
 	mux.HandleFunc("/status", s.handleStatus)
@@ -89,9 +135,16 @@ func (s *Server) handlePut(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Only the leader can accept writes.
-	if s.cfg.Role != cluster.Leader {
-		s.respondError(w, http.StatusForbidden, "not a leader")
+	// Only the current Raft leader can accept writes; anyone else
+	// redirects the client to the node it believes is leader.
+	if !s.raft.IsLeader() {
+		leader := s.raft.LeaderID()
+		if leader == "" {
+			s.respondError(w, http.StatusServiceUnavailable, "no leader elected yet")
+			return
+		}
+		w.Header().Set("Location", leader+"/put")
+		s.respondError(w, http.StatusTemporaryRedirect, fmt.Sprintf("not leader; current leader is %s", leader))
 		return
 	}
 
@@ -106,48 +159,102 @@ func (s *Server) handlePut(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 2. Generate a unique ID and timestamp for this write.
+	// 2. Generate a unique ID and HLC timestamp for this write. Stamping
+	// with the leader's own clock (rather than a raw wall-clock read)
+	// keeps LWW ordering causally correct even if the leader's clock has
+	// drifted relative to its followers'.
 	reqID := uuid.NewString()
 	entry := store.Entry{
 		Key:   req.Key,
 		Value: req.Value,
-		TS:    time.Now().UTC(),
+		TS:    s.clock.Now(),
 	}
 
-	// 3. Write to the leader's own store.
+	// 3. Assign this write the next LSN and persist it to the WAL before
+	// it's applied anywhere, so a crash right after this point still
+	// recovers the write on restart.
+	lsn := s.wal.NextLSN()
+	if err := s.wal.Append(wal.Record{ReqID: reqID, Key: entry.Key, Value: entry.Value, TS: entry.TS, LSN: lsn}); err != nil {
+		s.respondError(w, http.StatusInternalServerError, fmt.Sprintf("failed to persist write: %v", err))
+		return
+	}
+
+	// 4. Write to the leader's own store, then mark lsn applied so the
+	// later Raft Apply backstop for this same entry (see
+	// consensus.Config.Apply) sees it's already been applied through
+	// AppliedThrough and skips its own WAL append instead of duplicating
+	// this record under the same LSN.
 	s.store.Upsert(entry)
-	s.log.Printf("[ReqID %s] leader local upsert: %s=%s", reqID, entry.Key, entry.Value)
+	s.wal.MarkApplied(lsn)
+	s.log.Printf("[ReqID %s] leader local upsert: %s=%s (lsn=%d)", reqID, entry.Key, entry.Value, lsn)
 
-	// 4. Create the replication request for followers.
+	// 5. Create the replication request for followers.
 	replReq := repl.ReplicateRequest{
 		Key:   entry.Key,
 		Value: entry.Value,
 		TS:    entry.TS,
 		ReqID: reqID,
+		LSN:   lsn,
+	}
+
+	// 6. Resolve the write concern for this request: a `?w=` query
+	// override takes priority over the node's configured default.
+	wc := s.cfg.WriteConcern
+	if override := r.URL.Query().Get("w"); override != "" {
+		parsed, err := cluster.ParseWriteConcern(override)
+		if err != nil {
+			s.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		wc = parsed
 	}
 
-	// 5. Broadcast to followers based on the mode (sync or async).
-	if s.cfg.Mode == cluster.Async {
-		// Asynchronous: respond to client immediately and replicate in the background.
-		go s.broadcastReplication(replReq)
-		s.respondJSON(w, http.StatusOK, PutResponse{
-			Status: "ok",
-			Mode:   string(s.cfg.Mode),
-			ReqID:  reqID,
-		})
-	} else {
-		// Synchronous: block until replication is complete.
-		s.broadcastReplication(replReq)
-		s.respondJSON(w, http.StatusOK, PutResponse{
-			Status: "ok",
-			Mode:   string(s.cfg.Mode),
-			ReqID:  reqID,
-		})
+	ctx, cancel := context.WithTimeout(r.Context(), s.writeTimeout)
+	defer cancel()
+
+	// 7. Record the write in the Raft log so the cluster agrees on an
+	// order for it and a majority has durably acknowledged it. This wait
+	// is unconditional regardless of write concern: it's what makes the
+	// write durable against a leader crash, not just visible to clients.
+	// It's bounded by ctx rather than blocking forever, since a
+	// partitioned majority (or this node losing leadership mid-wait,
+	// which closes Done without committing — see becomeFollowerLocked)
+	// must not hang the HTTP handler goroutine indefinitely.
+	result, err := s.raft.Propose(replReq)
+	if err != nil {
+		// Lost leadership between the check above and here.
+		s.respondError(w, http.StatusServiceUnavailable, "lost leadership, retry")
+		return
+	}
+	select {
+	case <-result.Done:
+	case <-ctx.Done():
+		s.log.Printf("[ReqID %s] timed out waiting for raft majority commit", reqID)
+	}
+	if !s.raft.IsLeader() {
+		s.respondError(w, http.StatusServiceUnavailable, "lost leadership before write was committed, retry")
+		return
 	}
+
+	// 8. Replicate to follower stores until wc is satisfied or
+	// writeTimeout elapses; any peer still outstanding after that keeps
+	// retrying in the background (see peer.go), so the client's ack
+	// reflects wc even though the cluster keeps converging afterwards.
+	acks := s.broadcastReplication(ctx, replReq, wc)
+
+	s.respondJSON(w, http.StatusOK, PutResponse{
+		Status:       "ok",
+		WriteConcern: wc.String(),
+		Acks:         acks,
+		ReqID:        reqID,
+	})
 }
 
-// handleGet handles a client's read request.
-// This can be served by either a leader or a follower.
+// handleGet handles a client's read request. This can be served by
+// either a leader or a follower; ?consistency= picks how strict the read
+// needs to be (see handleGetLinearizable and lease.go for the
+// "linearizable" and "leader" modes). The default, "eventual", is the
+// store's own best-effort local read.
 func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		s.respondError(w, http.StatusMethodNotAllowed, "method not allowed")
@@ -160,12 +267,27 @@ func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	switch consistency := r.URL.Query().Get("consistency"); consistency {
+	case "", "eventual":
+		s.respondEntry(w, key)
+	case "leader":
+		s.proxyGetToLeader(w, key)
+	case "linearizable":
+		s.handleGetLinearizable(w, r, key)
+	default:
+		s.respondError(w, http.StatusBadRequest, fmt.Sprintf("unknown consistency %q (want eventual, linearizable, or leader)", consistency))
+	}
+}
+
+// respondEntry looks key up in the local store and writes it (or a 404)
+// to w, with no consistency guarantee beyond whatever this node has
+// applied so far.
+func (s *Server) respondEntry(w http.ResponseWriter, key string) {
 	entry, ok := s.store.Get(key)
 	if !ok {
 		s.respondError(w, http.StatusNotFound, "key not found")
 		return
 	}
-
 	s.respondJSON(w, http.StatusOK, entry)
 }
 
@@ -180,7 +302,7 @@ func (s *Server) handleReplicate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Followers should not replicate to other nodes.
-	if s.cfg.Role == cluster.Leader {
+	if s.raft.IsLeader() {
 		s.respondError(w, http.StatusForbidden, "leader cannot replicate to itself")
 		return
 	}
@@ -192,20 +314,140 @@ func (s *Server) handleReplicate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.log.Printf("[ReqID %s] follower replicating: %s=%s", req.ReqID, req.Key, req.Value)
+	// 2. Persist it to the WAL and apply it to the local store.
+	if err := s.ApplyReplicated(req); err != nil {
+		s.respondError(w, http.StatusInternalServerError, fmt.Sprintf("failed to apply replicated write: %v", err))
+		return
+	}
 
-	// 2. Create the store Entry from the request.
-	entry := store.Entry{
-		Key:   req.Key,
-		Value: req.Value,
-		TS:    req.TS,
+	// 3. Acknowledge the write.
+	s.respondJSON(w, http.StatusOK, repl.ReplicateResponse{Status: "ok"})
+}
+
+// ApplyReplicated persists req to this node's WAL (using the LSN the
+// leader already assigned) and then upserts it into the local store.
+// It's the common apply path for every way a write can reach a follower:
+// the HTTP /replicate handler and the gRPC replication server call it
+// for the leader's direct, write-concern-aware push, and
+// cmd/server/main.go also wires it in as consensus.Config.Apply, so
+// Raft's own committed-log replication invokes it too as a durability
+// backstop — a follower that missed the direct push (e.g. it was
+// partitioned when broadcastReplication ran) still converges once its
+// Raft log catches up and commits the entry, independently of the
+// direct push's own per-peer retry queue. Guarded below by LSN against
+// AppliedThrough so being invoked twice for the same write, once from
+// each path, is a harmless no-op the second time. The guard only covers
+// LSNs at or below AppliedThrough: an LSN that was applied ahead of a gap
+// and is still pending is appended to the WAL and upserted again.
+//
+// Before storing, it folds the leader's HLC timestamp into this node's
+// own clock via Clock.Update, so the timestamp a follower serves back on
+// read is itself a valid HLC value and later local writes are ordered
+// after every write it has seen. A timestamp whose physical component
+// has drifted too far ahead of this node's wall clock is rejected rather
+// than accepted verbatim, so one node's broken clock can't reorder
+// history for the rest of the cluster.
+func (s *Server) ApplyReplicated(req repl.ReplicateRequest) error {
+	if req.LSN <= s.wal.AppliedThrough() {
+		s.log.Printf("[ReqID %s] lsn=%d already applied (through=%d), skipping duplicate apply", req.ReqID, req.LSN, s.wal.AppliedThrough())
+		return nil
 	}
 
-	// 3. Upsert to the local store using LWW logic.
-	s.store.Upsert(entry)
+	s.log.Printf("[ReqID %s] replicating: %s=%s (lsn=%d)", req.ReqID, req.Key, req.Value, req.LSN)
 
-	// 4. Acknowledge the write.
-	s.respondJSON(w, http.StatusOK, repl.ReplicateResponse{Status: "ok"})
+	ts, err := s.clock.Update(req.TS)
+	if err != nil {
+		return fmt.Errorf("updating hlc: %w", err)
+	}
+
+	if err := s.wal.Append(wal.Record{ReqID: req.ReqID, Key: req.Key, Value: req.Value, TS: ts, LSN: req.LSN}); err != nil {
+		return fmt.Errorf("persisting to WAL: %w", err)
+	}
+
+	s.store.Upsert(store.Entry{Key: req.Key, Value: req.Value, TS: ts})
+	// Only now, with the write visible in the store, is it safe to let a
+	// linearizable read gated on AppliedThrough proceed past this LSN.
+	s.wal.MarkApplied(req.LSN)
+	return nil
+}
+
+// handleReplicateSince lets a follower that was partitioned or just
+// restarted catch up incrementally instead of relying on the leader
+// retrying individual writes forever. It streams every WAL record with
+// an LSN greater than the requested one back as newline-delimited JSON.
+func (s *Server) handleReplicateSince(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.respondError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	lsn, err := strconv.ParseUint(r.URL.Query().Get("lsn"), 10, 64)
+	if err != nil {
+		s.respondError(w, http.StatusBadRequest, "invalid or missing lsn query param")
+		return
+	}
+
+	records, err := s.wal.Since(lsn)
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, fmt.Sprintf("failed to read WAL: %v", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	flusher, canFlush := w.(http.Flusher)
+	for _, rec := range records {
+		replReq := repl.ReplicateRequest{Key: rec.Key, Value: rec.Value, TS: rec.TS, ReqID: rec.ReqID, LSN: rec.LSN}
+		if err := enc.Encode(replReq); err != nil {
+			s.log.Printf("ERROR: failed to stream catch-up record: %v", err)
+			return
+		}
+		if canFlush {
+			flusher.Flush()
+		}
+	}
+}
+
+// --- Raft Consensus Handlers ---
+
+// handleRaftVote is the endpoint peers call to request this node's vote
+// in a leader election.
+func (s *Server) handleRaftVote(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.respondError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var args consensus.RequestVoteArgs
+	if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+		s.respondError(w, http.StatusBadRequest, "invalid vote request body")
+		return
+	}
+	s.respondJSON(w, http.StatusOK, s.raft.HandleRequestVote(args))
+}
+
+// handleRaftAppend is the endpoint the leader calls to send heartbeats
+// and log entries to this node.
+func (s *Server) handleRaftAppend(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.respondError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var args consensus.AppendEntriesArgs
+	if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+		s.respondError(w, http.StatusBadRequest, "invalid append entries body")
+		return
+	}
+	s.respondJSON(w, http.StatusOK, s.raft.HandleAppendEntries(args))
+}
+
+// handleRaftStatus reports this node's Raft term, role and log length.
+func (s *Server) handleRaftStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.respondError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	s.respondJSON(w, http.StatusOK, s.raft.Status())
 }
 
 // --- Admin & Status Handlers ---
@@ -227,13 +469,16 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 	s.mu.Unlock()
 
 	status := Status{
-		ID:      s.cfg.ID,
-		Role:    string(s.cfg.Role),
-		Mode:    string(s.cfg.Mode),
-		Port:    s.cfg.Port,
-		Peers:   s.cfg.Peers,
-		Data:    s.store.Snapshot(), // Get a safe copy of the data
-		Blocked: blocked,
+		ID:           s.cfg.ID,
+		Role:         s.raft.Status().Role,
+		WriteConcern: s.cfg.WriteConcern.String(),
+		Port:         s.cfg.Port,
+		Peers:        s.cfg.Peers,
+		Data:         s.store.Snapshot(), // Get a safe copy of the data
+		Blocked:      blocked,
+	}
+	if s.raft.IsLeader() {
+		status.PeerLag = s.peerLagSnapshot()
 	}
 
 	s.respondJSON(w, http.StatusOK, status)
@@ -270,50 +515,95 @@ func (s *Server) handlePartition(w http.ResponseWriter, r *http.Request) {
 
 // --- Helper Methods ---
 
-// broadcastReplication sends the replication request to all configured peers.
-// It respects the BlockPeers map for partition testing.
-// In sync mode, it blocks. In async mode, it does not.
-func (s *Server) broadcastReplication(req repl.ReplicateRequest) {
-	s.log.Printf("[ReqID %s] broadcasting to %d peers", req.ReqID, len(s.cfg.Peers))
-
-	// Use a WaitGroup to track all replication goroutines.
-	// This is necessary for *both* sync and async, but in async,
-	// we just don't wait on it. In a real system, you'd still
-	// want to know when the async ops finish, e.g., for metrics.
-	var wg sync.WaitGroup
-
-	for _, peerURL := range s.cfg.Peers {
-		wg.Add(1)
-		// Launch a separate goroutine for each peer.
-		go func(url string) {
-			defer wg.Done()
+// broadcastReplicationTimeout bounds each individual peer Replicate call.
+// It's independent of the caller's request context so that a peer which
+// is about to succeed isn't canceled out from under it just because
+// handlePut's own write-concern wait has returned.
+const broadcastReplicationTimeout = 5 * time.Second
+
+// broadcastReplication sends req to every configured peer (skipping any
+// blocked for partition testing) and waits until either wc is satisfied
+// or s.writeTimeout passes, returning the number of follower acks
+// observed. Any peer that hasn't responded yet when broadcastReplication
+// returns — because quorum was already reached, or the deadline hit —
+// is still tracked to completion in the background: a failure there is
+// handed to that peer's retry queue instead of being dropped, so the
+// cluster still converges even though the client didn't wait for it.
+func (s *Server) broadcastReplication(ctx context.Context, req repl.ReplicateRequest, wc cluster.WriteConcern) int {
+	peers := s.cfg.Peers
+	need := wc.RequiredAcks(len(peers))
+	s.log.Printf("[ReqID %s] broadcasting to %d peers, need %d acks (w=%s)", req.ReqID, len(peers), need, wc)
+
+	type ackResult struct {
+		peer string
+		err  error
+	}
+	resultCh := make(chan ackResult, len(peers))
 
-			// Check if this peer is partitioned (blocked).
+	for _, peerURL := range peers {
+		go func(url string) {
 			s.mu.Lock()
 			isBlocked := s.cfg.BlockPeers[url]
 			s.mu.Unlock()
 
 			if isBlocked {
-				s.log.Printf("[ReqID %s] skipped replication to %s (blocked)", req.ReqID, url)
+				resultCh <- ackResult{url, fmt.Errorf("peer blocked")}
 				return
 			}
 
-			// Send the replication request.
-			err := repl.PostReplicate(s.client, url, req)
-			if err != nil {
-				s.log.Printf("[ReqID %s] ERROR replicating to %s: %v", req.ReqID, url, err)
-			} else {
-				s.log.Printf("[ReqID %s] replicated to %s successfully", req.ReqID, url)
-			}
+			// Deliberately not derived from ctx: handlePut cancels ctx as
+			// soon as this function returns, which must not abort a send
+			// that's still in flight in the background drain below.
+			rctx, cancel := context.WithTimeout(context.Background(), broadcastReplicationTimeout)
+			defer cancel()
+			resultCh <- ackResult{url, s.transport.Replicate(rctx, url, []repl.ReplicateRequest{req})}
 		}(peerURL)
 	}
 
-	// In sync mode, we block until all goroutines are done.
-	if s.cfg.Mode == cluster.Sync {
-		s.log.Printf("[ReqID %s] waiting for sync replication...", req.ReqID)
-		wg.Wait()
-		s.log.Printf("[ReqID %s] sync replication complete.", req.ReqID)
+	acks, received := 0, 0
+	if need > 0 {
+		timeout := time.NewTimer(s.writeTimeout)
+		defer timeout.Stop()
+
+	waitLoop:
+		for received < len(peers) {
+			select {
+			case res := <-resultCh:
+				received++
+				if res.err == nil {
+					acks++
+					s.ackPeer(res.peer, req.LSN)
+					if acks >= need {
+						break waitLoop
+					}
+				} else {
+					s.log.Printf("[ReqID %s] replication to %s failed, queuing for retry: %v", req.ReqID, res.peer, res.err)
+					s.peerFor(res.peer).enqueue(req)
+				}
+			case <-timeout.C:
+				s.log.Printf("[ReqID %s] write concern timed out with %d/%d acks", req.ReqID, acks, need)
+				break waitLoop
+			case <-ctx.Done():
+				break waitLoop
+			}
+		}
+	}
+
+	if remaining := len(peers) - received; remaining > 0 {
+		go func() {
+			for i := 0; i < remaining; i++ {
+				res := <-resultCh
+				if res.err != nil {
+					s.log.Printf("[ReqID %s] late replication to %s failed, queuing for retry: %v", req.ReqID, res.peer, res.err)
+					s.peerFor(res.peer).enqueue(req)
+				} else {
+					s.ackPeer(res.peer, req.LSN)
+				}
+			}
+		}()
 	}
+
+	return acks
 }
 
 // respondJSON is a helper to write a JSON response.
@@ -0,0 +1,247 @@
+// internal/api/lease.go
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Chinzzii/leader-replication-go/internal/repl"
+)
+
+// leaseInterval is how often the leader pushes a fresh LeaseGrant to
+// every peer; leaseDuration is how long each grant is valid for once
+// received. Pushing well before expiry (2s push / 5s lease) means a
+// follower serving linearizable reads only goes stale if it misses
+// several pushes in a row, e.g. during a partition.
+const (
+	leaseInterval = 2 * time.Second
+	leaseDuration = 5 * time.Second
+)
+
+// defaultLinearizableTimeout bounds how long handleGet waits for a
+// follower to catch up to a lease's high watermark when the client
+// doesn't supply its own ?timeout=.
+const defaultLinearizableTimeout = 2 * time.Second
+
+// LeaseGrant lets a follower serve a linearizable read locally without
+// asking the leader on every request: while lease.ExpiresAt hasn't
+// passed and this node has applied at least HighWatermarkLSN, its local
+// store is guaranteed to reflect every write the leader had committed
+// when it issued the grant.
+type LeaseGrant struct {
+	LeaderID         string    `json:"leader_id"`
+	Term             uint64    `json:"term"`
+	IssuedAt         time.Time `json:"issued_at"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	HighWatermarkLSN uint64    `json:"high_watermark_lsn"`
+}
+
+// StartLeaseLoop launches the background loop that, while this node is
+// leader, pushes a LeaseGrant to every peer every leaseInterval, and
+// pushes one final already-expired grant on the first tick after stepping
+// down (so up to leaseInterval later) so
+// a partitioned old leader doesn't keep serving stale linearizable reads
+// until its last lease naturally expires.
+func (s *Server) StartLeaseLoop() (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(leaseInterval)
+		defer ticker.Stop()
+
+		wasLeader := false
+		for {
+			select {
+			case <-ticker.C:
+				isLeader := s.raft.IsLeader()
+				switch {
+				case isLeader:
+					s.grantLeases()
+				case wasLeader:
+					s.revokeLeases()
+				}
+				wasLeader = isLeader
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// grantLeases pushes a fresh LeaseGrant, valid for leaseDuration, to
+// every peer that isn't currently partitioned for testing.
+func (s *Server) grantLeases() {
+	grant := LeaseGrant{
+		LeaderID:         s.cfg.BaseURL(),
+		Term:             s.raft.Status().Term,
+		IssuedAt:         time.Now(),
+		ExpiresAt:        time.Now().Add(leaseDuration),
+		HighWatermarkLSN: s.wal.LastLSN(),
+	}
+	for _, peer := range s.unblockedPeers() {
+		go s.pushLease(peer, grant)
+	}
+}
+
+// revokeLeases pushes an already-expired grant at the current term to
+// every peer, so a node that just lost leadership invalidates its
+// followers' leases immediately rather than waiting out leaseDuration.
+func (s *Server) revokeLeases() {
+	grant := LeaseGrant{
+		LeaderID:  s.cfg.BaseURL(),
+		Term:      s.raft.Status().Term,
+		IssuedAt:  time.Now(),
+		ExpiresAt: time.Now().Add(-time.Second),
+	}
+	peers := s.unblockedPeers()
+	s.log.Printf("lease: stepped down as leader, revoking leases on %d peers", len(peers))
+	for _, peer := range peers {
+		go s.pushLease(peer, grant)
+	}
+}
+
+// unblockedPeers returns cfg.Peers minus whichever are currently
+// partitioned for testing via BlockPeers.
+func (s *Server) unblockedPeers() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	peers := make([]string, 0, len(s.cfg.Peers))
+	for _, peer := range s.cfg.Peers {
+		if !s.cfg.BlockPeers[peer] {
+			peers = append(peers, peer)
+		}
+	}
+	return peers
+}
+
+func (s *Server) pushLease(peer string, grant LeaseGrant) {
+	b, err := json.Marshal(grant)
+	if err != nil {
+		s.log.Printf("lease: marshaling grant for %s: %v", peer, err)
+		return
+	}
+	resp, err := s.httpClient.Post(peer+"/lease", "application/json", bytes.NewReader(b))
+	if err != nil {
+		s.log.Printf("lease: pushing to %s failed: %v", peer, err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		s.log.Printf("lease: pushing to %s rejected: status %d", peer, resp.StatusCode)
+	}
+}
+
+// handleLease is the endpoint a follower exposes for the leader to push
+// LeaseGrant updates (and revocations) to.
+func (s *Server) handleLease(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.respondError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var grant LeaseGrant
+	if err := json.NewDecoder(r.Body).Decode(&grant); err != nil {
+		s.respondError(w, http.StatusBadRequest, "invalid lease grant body")
+		return
+	}
+
+	s.leaseMu.Lock()
+	// Accept a grant from a strictly newer term outright (a newer leader
+	// always supersedes an older one), or a same-term grant that was
+	// issued later than the one we're already holding. Comparing Term
+	// alone would let a same-term grant that was merely delayed in
+	// flight clobber a fresher one delivered first; comparing on
+	// ExpiresAt instead would misorder revokes, whose ExpiresAt is
+	// deliberately set in the past.
+	if s.lease == nil || grant.Term > s.lease.Term ||
+		(grant.Term == s.lease.Term && grant.IssuedAt.After(s.lease.IssuedAt)) {
+		s.lease = &grant
+	}
+	s.leaseMu.Unlock()
+
+	s.respondJSON(w, http.StatusOK, repl.ReplicateResponse{Status: "ok"})
+}
+
+// handleGetLinearizable serves key with a linearizability guarantee: the
+// leader always has the latest data and can answer directly; a follower
+// may only answer once its local WAL has caught up to a currently valid
+// lease's high watermark, and otherwise waits (bounded by an optional
+// client-supplied ?timeout=, e.g. "500ms") before falling back to a 503
+// pointing at the leader.
+func (s *Server) handleGetLinearizable(w http.ResponseWriter, r *http.Request, key string) {
+	if s.raft.IsLeader() {
+		s.respondEntry(w, key)
+		return
+	}
+
+	timeout := defaultLinearizableTimeout
+	if raw := r.URL.Query().Get("timeout"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			s.respondError(w, http.StatusBadRequest, fmt.Sprintf("invalid timeout %q: %v", raw, err))
+			return
+		}
+		timeout = d
+	}
+
+	const pollInterval = 10 * time.Millisecond
+	deadline := time.Now().Add(timeout)
+	for {
+		if s.hasValidLeaseFor(s.wal.AppliedThrough()) {
+			s.respondEntry(w, key)
+			return
+		}
+		if time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(pollInterval)
+	}
+
+	leader := s.raft.LeaderID()
+	if leader != "" {
+		w.Header().Set("Location", fmt.Sprintf("%s/get?key=%s&consistency=linearizable", leader, url.QueryEscape(key)))
+	}
+	s.respondError(w, http.StatusServiceUnavailable, "no lease covering the latest writes yet; retry or follow Location")
+}
+
+// hasValidLeaseFor reports whether this node currently holds an
+// unexpired lease whose high watermark is covered by appliedLSN.
+func (s *Server) hasValidLeaseFor(appliedLSN uint64) bool {
+	s.leaseMu.Lock()
+	lease := s.lease
+	s.leaseMu.Unlock()
+	return lease != nil && time.Now().Before(lease.ExpiresAt) && appliedLSN >= lease.HighWatermarkLSN
+}
+
+// proxyGetToLeader forwards a read to the current leader's own /get and
+// relays its response verbatim, for clients that want the leader's view
+// without paying for a full lease-backed linearizable read locally.
+func (s *Server) proxyGetToLeader(w http.ResponseWriter, key string) {
+	if s.raft.IsLeader() {
+		s.respondEntry(w, key)
+		return
+	}
+
+	leader := s.raft.LeaderID()
+	if leader == "" {
+		s.respondError(w, http.StatusServiceUnavailable, "no leader elected yet")
+		return
+	}
+
+	target := fmt.Sprintf("%s/get?key=%s&consistency=eventual", leader, url.QueryEscape(key))
+	resp, err := s.httpClient.Get(target)
+	if err != nil {
+		s.respondError(w, http.StatusBadGateway, fmt.Sprintf("proxying to leader %s: %v", leader, err))
+		return
+	}
+	defer resp.Body.Close()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(resp.StatusCode)
+	io.Copy(w, resp.Body)
+}
@@ -0,0 +1,156 @@
+// internal/api/lease_test.go
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Chinzzii/leader-replication-go/internal/hlc"
+	"github.com/Chinzzii/leader-replication-go/internal/repl"
+	"github.com/Chinzzii/leader-replication-go/internal/store"
+	"github.com/Chinzzii/leader-replication-go/internal/wal"
+)
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	dir := t.TempDir()
+	w, err := wal.Open(dir)
+	if err != nil {
+		t.Fatalf("wal.Open: %v", err)
+	}
+	t.Cleanup(func() { w.Close() })
+
+	kv, err := store.New(w)
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+
+	logger := log.New(os.Stderr, "", 0)
+	return NewServer(nil, kv, nil, w, nil, hlc.New(hlc.DefaultMaxDrift), logger)
+}
+
+func TestHasValidLeaseFor(t *testing.T) {
+	s := newTestServer(t)
+
+	if s.hasValidLeaseFor(0) {
+		t.Fatal("hasValidLeaseFor: got true with no lease held, want false")
+	}
+
+	s.leaseMu.Lock()
+	s.lease = &LeaseGrant{
+		Term:             1,
+		IssuedAt:         time.Now(),
+		ExpiresAt:        time.Now().Add(time.Minute),
+		HighWatermarkLSN: 10,
+	}
+	s.leaseMu.Unlock()
+
+	if s.hasValidLeaseFor(5) {
+		t.Error("hasValidLeaseFor(5): got true with watermark 10 not yet applied, want false")
+	}
+	if !s.hasValidLeaseFor(10) {
+		t.Error("hasValidLeaseFor(10): got false, want true (watermark exactly covered)")
+	}
+
+	s.leaseMu.Lock()
+	s.lease.ExpiresAt = time.Now().Add(-time.Second)
+	s.leaseMu.Unlock()
+
+	if s.hasValidLeaseFor(10) {
+		t.Error("hasValidLeaseFor(10): got true for an expired lease, want false")
+	}
+}
+
+func postLease(t *testing.T, s *Server, grant LeaseGrant) {
+	t.Helper()
+	b, err := json.Marshal(grant)
+	if err != nil {
+		t.Fatalf("marshaling grant: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/lease", bytes.NewReader(b))
+	rec := httptest.NewRecorder()
+	s.handleLease(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("handleLease: status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestHandleLeaseAcceptanceOrdering(t *testing.T) {
+	s := newTestServer(t)
+
+	t0 := time.Now()
+	postLease(t, s, LeaseGrant{Term: 1, IssuedAt: t0})
+	if s.lease.Term != 1 {
+		t.Fatalf("lease.Term = %d after first grant, want 1", s.lease.Term)
+	}
+
+	// A same-term grant issued earlier than the one already held must not
+	// clobber it (it arrived late, e.g. reordered in flight).
+	postLease(t, s, LeaseGrant{Term: 1, IssuedAt: t0.Add(-time.Second)})
+	if !s.lease.IssuedAt.Equal(t0) {
+		t.Errorf("lease.IssuedAt = %v after a stale same-term grant, want unchanged %v", s.lease.IssuedAt, t0)
+	}
+
+	// A same-term grant issued later replaces the held one.
+	t1 := t0.Add(time.Second)
+	postLease(t, s, LeaseGrant{Term: 1, IssuedAt: t1})
+	if !s.lease.IssuedAt.Equal(t1) {
+		t.Errorf("lease.IssuedAt = %v after newer same-term grant, want %v", s.lease.IssuedAt, t1)
+	}
+
+	// A strictly higher term always wins, even with an earlier IssuedAt.
+	postLease(t, s, LeaseGrant{Term: 2, IssuedAt: t0.Add(-time.Hour)})
+	if s.lease.Term != 2 {
+		t.Errorf("lease.Term = %d after higher-term grant, want 2", s.lease.Term)
+	}
+}
+
+func TestApplyReplicatedIsIdempotent(t *testing.T) {
+	s := newTestServer(t)
+
+	req := repl.ReplicateRequest{
+		ReqID: "r1",
+		Key:   "a",
+		Value: "1",
+		TS:    hlc.Timestamp{Physical: 1},
+		LSN:   1,
+	}
+
+	if err := s.ApplyReplicated(req); err != nil {
+		t.Fatalf("ApplyReplicated: %v", err)
+	}
+	if s.wal.AppliedThrough() != 1 {
+		t.Fatalf("AppliedThrough() = %d after first apply, want 1", s.wal.AppliedThrough())
+	}
+
+	entry, ok := s.store.Get("a")
+	if !ok || entry.Value != "1" {
+		t.Fatalf("store.Get(\"a\") = (%+v, %v), want (\"1\", true)", entry, ok)
+	}
+
+	// Re-applying the same (or an older) LSN must be a no-op: it must not
+	// error and must not re-append to the WAL.
+	req2 := repl.ReplicateRequest{ReqID: "r1-dup", Key: "a", Value: "2", TS: hlc.Timestamp{Physical: 2}, LSN: 1}
+	if err := s.ApplyReplicated(req2); err != nil {
+		t.Fatalf("ApplyReplicated (duplicate lsn): %v", err)
+	}
+
+	entry, ok = s.store.Get("a")
+	if !ok || entry.Value != "1" {
+		t.Fatalf("store.Get(\"a\") after duplicate apply = (%+v, %v), want value still \"1\"", entry, ok)
+	}
+
+	records, err := s.wal.Since(0)
+	if err != nil {
+		t.Fatalf("wal.Since(0): %v", err)
+	}
+	if len(records) != 1 {
+		t.Errorf("WAL holds %d records after duplicate apply, want 1", len(records))
+	}
+}
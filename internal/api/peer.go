@@ -0,0 +1,158 @@
+// internal/api/peer.go
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Chinzzii/leader-replication-go/internal/repl"
+)
+
+// minRetryBackoff and maxRetryBackoff bound the exponential backoff used
+// to retry replication to a peer that's down or slow: it starts fast and
+// doubles after each failure up to a 30s cap, so a long partition doesn't
+// turn into a tight retry loop.
+const (
+	minRetryBackoff = 100 * time.Millisecond
+	maxRetryBackoff = 30 * time.Second
+)
+
+// peerState tracks one follower's replication progress and holds the
+// queue of writes that missed quorum and need to be retried in the
+// background until they eventually land, giving the cluster eventual
+// consistency beyond whatever write concern the client asked for.
+type peerState struct {
+	url string
+
+	mu           sync.Mutex
+	lastAckedLSN uint64
+	queue        []repl.ReplicateRequest
+	backoff      time.Duration
+
+	notify chan struct{}
+}
+
+// peerFor returns the peerState for url, creating it (and starting its
+// retry worker) on first use.
+func (s *Server) peerFor(url string) *peerState {
+	s.peersMu.Lock()
+	defer s.peersMu.Unlock()
+
+	p, ok := s.peers[url]
+	if !ok {
+		p = &peerState{
+			url:     url,
+			backoff: minRetryBackoff,
+			notify:  make(chan struct{}, 1),
+		}
+		s.peers[url] = p
+		go s.retryWorker(p)
+	}
+	return p
+}
+
+// ackPeer records that url has applied the write at lsn, advancing its
+// lastAckedLSN if lsn is newer than what's already recorded.
+func (s *Server) ackPeer(url string, lsn uint64) {
+	p := s.peerFor(url)
+	p.mu.Lock()
+	if lsn > p.lastAckedLSN {
+		p.lastAckedLSN = lsn
+	}
+	p.mu.Unlock()
+}
+
+// enqueue appends req to p's retry queue and wakes its retry worker.
+func (p *peerState) enqueue(req repl.ReplicateRequest) {
+	p.mu.Lock()
+	p.queue = append(p.queue, req)
+	p.mu.Unlock()
+
+	select {
+	case p.notify <- struct{}{}:
+	default: // worker is already awake or already has a pending wakeup
+	}
+}
+
+// retryWorker drains p's retry queue, one write at a time, backing off
+// exponentially between failures and resetting once a write succeeds. It
+// runs for the lifetime of the server.
+func (s *Server) retryWorker(p *peerState) {
+	for range p.notify {
+		for {
+			p.mu.Lock()
+			if len(p.queue) == 0 {
+				p.mu.Unlock()
+				break
+			}
+			req := p.queue[0]
+			backoff := p.backoff
+			p.mu.Unlock()
+
+			s.mu.Lock()
+			isBlocked := s.cfg.BlockPeers[p.url]
+			s.mu.Unlock()
+			if isBlocked {
+				// Still partitioned: leave the write at the head of the
+				// queue and wait for the same backoff as a failed send
+				// rather than busy-spinning until it's unblocked.
+				s.log.Printf("[ReqID %s] retry to %s skipped (blocked)", req.ReqID, p.url)
+				time.Sleep(backoff)
+				continue
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			err := s.transport.Replicate(ctx, p.url, []repl.ReplicateRequest{req})
+			cancel()
+
+			if err != nil {
+				s.log.Printf("[ReqID %s] retry to %s failed, backing off %s: %v", req.ReqID, p.url, backoff, err)
+				time.Sleep(backoff)
+				p.mu.Lock()
+				p.backoff *= 2
+				if p.backoff > maxRetryBackoff {
+					p.backoff = maxRetryBackoff
+				}
+				p.mu.Unlock()
+				continue
+			}
+
+			p.mu.Lock()
+			p.queue = p.queue[1:]
+			if req.LSN > p.lastAckedLSN {
+				p.lastAckedLSN = req.LSN
+			}
+			p.backoff = minRetryBackoff
+			p.mu.Unlock()
+			s.log.Printf("[ReqID %s] retry to %s succeeded", req.ReqID, p.url)
+		}
+	}
+}
+
+// peerLagSnapshot reports, for every configured peer, the gap between
+// this node's own WAL and the highest LSN that peer is known to have
+// acknowledged. It's used to populate /status while this node is
+// leading.
+func (s *Server) peerLagSnapshot() []PeerLag {
+	lastLSN := s.wal.LastLSN()
+
+	s.peersMu.Lock()
+	defer s.peersMu.Unlock()
+
+	out := make([]PeerLag, 0, len(s.cfg.Peers))
+	for _, url := range s.cfg.Peers {
+		var acked uint64
+		if p, ok := s.peers[url]; ok {
+			p.mu.Lock()
+			acked = p.lastAckedLSN
+			p.mu.Unlock()
+		}
+		lag := uint64(0)
+		if lastLSN > acked {
+			lag = lastLSN - acked
+		}
+		out = append(out, PeerLag{Peer: url, LastAckedLSN: acked, Lag: lag})
+	}
+	return out
+}
@@ -7,7 +7,10 @@ import (
 	"strings"
 )
 
-// Role defines the role of a node in the cluster.
+// Role defines the role of a node in the cluster. Unlike earlier
+// versions of this package, a node's Role is no longer fixed at startup:
+// it is reported by the internal/consensus Raft instance and changes as
+// leadership is won, lost, or contested.
 type Role string
 
 const (
@@ -15,21 +18,12 @@ const (
 	Follower Role = "follower"
 )
 
-// Mode defines the replication mode for the leader.
-type Mode string
-
-const (
-	Sync  Mode = "sync"  // Leader waits for followers to ACK before responding to client.
-	Async Mode = "async" // Leader responds to client immediately.
-)
-
 // NodeConfig holds all configuration for a single node.
 type NodeConfig struct {
-	ID    string   // Unique ID for this node (e.g., "leader-1")
-	Role  Role     // This node's role (leader or follower)
-	Mode  Mode     // Replication mode (sync or async), only used by leader
-	Port  int      // HTTP port this node listens on
-	Peers []string // List of peer base URLs (e.g., "http://follower1:8081")
+	ID           string       // Unique ID for this node (e.g., "leader-1")
+	WriteConcern WriteConcern // Default write concern for /put, only used while leader
+	Port         int          // HTTP port this node listens on
+	Peers        []string     // List of peer base URLs (e.g., "http://follower1:8081")
 
 	// BlockPeers is a map used to simulate network partitions for testing.
 	// If a peer's URL is a key in this map, the leader will not send
@@ -0,0 +1,98 @@
+// internal/cluster/writeconcern.go
+package cluster
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// writeConcernKind distinguishes the handful of write concerns /put
+// understands.
+type writeConcernKind int
+
+const (
+	wcOne writeConcernKind = iota // leader-only: don't wait on any peer
+	wcMajority
+	wcN // an explicit total ack count, including the leader
+	wcAll
+)
+
+// WriteConcern controls how many nodes must have a write before /put
+// responds to the client: the leader's own write always counts as one,
+// and the remainder is made up of follower acks.
+type WriteConcern struct {
+	kind writeConcernKind
+	n    int // only meaningful when kind == wcN
+}
+
+// WriteConcernOne, WriteConcernMajority and WriteConcernAll are the three
+// named concerns; use ParseWriteConcern for an explicit W=N.
+var (
+	WriteConcernOne      = WriteConcern{kind: wcOne}
+	WriteConcernMajority = WriteConcern{kind: wcMajority}
+	WriteConcernAll      = WriteConcern{kind: wcAll}
+)
+
+// ParseWriteConcern parses the -write-concern flag value or a /put
+// `?w=` query override: "1", "majority", "all", or a positive integer.
+func ParseWriteConcern(s string) (WriteConcern, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "1":
+		return WriteConcernOne, nil
+	case "majority":
+		return WriteConcernMajority, nil
+	case "all":
+		return WriteConcernAll, nil
+	default:
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			return WriteConcern{}, fmt.Errorf("invalid write concern %q (want 1, majority, all, or a positive integer)", s)
+		}
+		return WriteConcern{kind: wcN, n: n}, nil
+	}
+}
+
+// String renders the concern the way ParseWriteConcern expects it back.
+func (w WriteConcern) String() string {
+	switch w.kind {
+	case wcMajority:
+		return "majority"
+	case wcAll:
+		return "all"
+	case wcN:
+		return strconv.Itoa(w.n)
+	default:
+		return "1"
+	}
+}
+
+// RequiredAcks returns how many follower acknowledgements /put must
+// observe to satisfy this concern, given numPeers configured peers. The
+// leader's own local write already counts as one node, so e.g. W=majority
+// in a 3-node cluster (1 leader + 2 peers) needs 1 follower ack, and
+// W=1 needs none.
+func (w WriteConcern) RequiredAcks(numPeers int) int {
+	total := numPeers + 1 // peers plus the leader itself
+
+	var need int
+	switch w.kind {
+	case wcMajority:
+		need = total/2 + 1
+	case wcAll:
+		need = total
+	case wcN:
+		need = w.n
+	default: // wcOne
+		need = 1
+	}
+
+	acks := need - 1 // the leader's own write already satisfies one
+	if acks < 0 {
+		acks = 0
+	}
+	if acks > numPeers {
+		acks = numPeers
+	}
+	return acks
+}
@@ -0,0 +1,61 @@
+// internal/cluster/writeconcern_test.go
+package cluster
+
+import "testing"
+
+func TestParseWriteConcern(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    WriteConcern
+		wantErr bool
+	}{
+		{"", WriteConcernOne, false},
+		{"1", WriteConcernOne, false},
+		{"majority", WriteConcernMajority, false},
+		{"MAJORITY", WriteConcernMajority, false},
+		{"all", WriteConcernAll, false},
+		{"3", WriteConcern{kind: wcN, n: 3}, false},
+		{"0", WriteConcern{}, true},
+		{"-1", WriteConcern{}, true},
+		{"bogus", WriteConcern{}, true},
+	}
+	for _, c := range cases {
+		got, err := ParseWriteConcern(c.in)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("ParseWriteConcern(%q): got nil error, want error", c.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseWriteConcern(%q): unexpected error %v", c.in, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("ParseWriteConcern(%q) = %+v, want %+v", c.in, got, c.want)
+		}
+	}
+}
+
+func TestWriteConcernRequiredAcks(t *testing.T) {
+	cases := []struct {
+		name     string
+		wc       WriteConcern
+		numPeers int
+		want     int
+	}{
+		{"one never waits", WriteConcernOne, 4, 0},
+		{"majority in 3-node cluster needs 1 follower ack", WriteConcernMajority, 2, 1},
+		{"majority in 5-node cluster needs 2 follower acks", WriteConcernMajority, 4, 2},
+		{"all waits on every peer", WriteConcernAll, 4, 4},
+		{"explicit N=3 needs 2 follower acks (leader counts as 1)", WriteConcern{kind: wcN, n: 3}, 4, 2},
+		{"explicit N capped at numPeers", WriteConcern{kind: wcN, n: 100}, 2, 2},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.wc.RequiredAcks(c.numPeers); got != c.want {
+				t.Errorf("RequiredAcks(%d) = %d, want %d", c.numPeers, got, c.want)
+			}
+		})
+	}
+}
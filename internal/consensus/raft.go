@@ -0,0 +1,680 @@
+// internal/consensus/raft.go
+package consensus
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Chinzzii/leader-replication-go/internal/cluster"
+	"github.com/Chinzzii/leader-replication-go/internal/repl"
+)
+
+// Candidate is the additional Role value a node takes on while it is
+// soliciting votes. cluster.Leader and cluster.Follower cover the other
+// two states a Raft node can be in.
+const Candidate cluster.Role = "candidate"
+
+// LogEntry is a single command in the replicated Raft log.
+type LogEntry struct {
+	Term    uint64                `json:"term"`
+	Index   uint64                `json:"index"`
+	Command repl.ReplicateRequest `json:"command"`
+}
+
+// Config holds everything a Raft instance needs from the rest of the node.
+type Config struct {
+	NodeID string // human-readable id, e.g. "node-1" (used for logs/state file)
+	Self   string // this node's base URL, used as its Raft identity on the wire
+	Peers  []string
+	Client *http.Client
+	Logger Logger
+
+	// StatePath is where persistent state (currentTerm, votedFor, log) is
+	// written so a restarted node doesn't forget who it voted for.
+	StatePath string
+
+	// Apply is invoked once a log entry has been committed by a majority,
+	// in commit order, on both the leader and every follower. cmd/server/main.go
+	// wires this to (*api.Server).ApplyReplicated, which is idempotent
+	// (guarded by LSN against the WAL's AppliedThrough watermark), so it's
+	// safe to invoke here even though the leader's own handlePut and the
+	// direct per-peer push in internal/api may already have applied the
+	// same entry through that faster, write-concern-aware path. This
+	// callback is what gives a follower a second, independent way to
+	// converge: Raft's own AppendEntries replication keeps retrying and
+	// filling log gaps regardless of whether the direct push's per-peer
+	// retry queue ever reaches it. Apply may be nil if the caller doesn't
+	// need a callback.
+	Apply func(repl.ReplicateRequest)
+
+	ElectionTimeoutMin time.Duration
+	ElectionTimeoutMax time.Duration
+	HeartbeatInterval  time.Duration
+}
+
+// Logger is the subset of *log.Logger that Raft needs; it lets callers
+// pass the same *log.Logger the rest of the server uses.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// Status is a snapshot of a Raft node's state, returned by /raft/status.
+type Status struct {
+	ID          string `json:"id"`
+	Term        uint64 `json:"term"`
+	Role        string `json:"role"`
+	LeaderID    string `json:"leader_id"`
+	LogLength   int    `json:"log_length"`
+	CommitIndex uint64 `json:"commit_index"`
+	LastApplied uint64 `json:"last_applied"`
+}
+
+// RequestVoteArgs is the payload for the RequestVote RPC (§5.2).
+type RequestVoteArgs struct {
+	Term         uint64 `json:"term"`
+	CandidateID  string `json:"candidate_id"`
+	LastLogIndex uint64 `json:"last_log_index"`
+	LastLogTerm  uint64 `json:"last_log_term"`
+}
+
+// RequestVoteReply is the response to a RequestVote RPC.
+type RequestVoteReply struct {
+	Term        uint64 `json:"term"`
+	VoteGranted bool   `json:"vote_granted"`
+}
+
+// AppendEntriesArgs is the payload for the AppendEntries RPC (§5.3). An
+// empty Entries slice is a heartbeat.
+type AppendEntriesArgs struct {
+	Term         uint64     `json:"term"`
+	LeaderID     string     `json:"leader_id"`
+	PrevLogIndex uint64     `json:"prev_log_index"`
+	PrevLogTerm  uint64     `json:"prev_log_term"`
+	Entries      []LogEntry `json:"entries"`
+	LeaderCommit uint64     `json:"leader_commit"`
+}
+
+// AppendEntriesReply is the response to an AppendEntries RPC.
+type AppendEntriesReply struct {
+	Term    uint64 `json:"term"`
+	Success bool   `json:"success"`
+}
+
+// persistentState is the part of a Raft node's state that must survive a
+// restart: currentTerm, votedFor and the log itself.
+type persistentState struct {
+	CurrentTerm uint64     `json:"current_term"`
+	VotedFor    string     `json:"voted_for"`
+	Log         []LogEntry `json:"log"`
+}
+
+// Raft implements leader election and log replication for a single node,
+// following Ongaro & Ousterhout's "In Search of an Understandable
+// Consensus Algorithm". It does not talk HTTP itself; internal/api wires
+// its RequestVote/AppendEntries handlers to /raft/vote and /raft/append,
+// and Raft makes outbound RPCs through cfg.Client.
+type Raft struct {
+	cfg Config
+
+	mu sync.Mutex
+
+	// Persistent state. log is 1-indexed; log[0] is a zero-value sentinel
+	// so PrevLogIndex/PrevLogTerm arithmetic never has to special-case an
+	// empty log.
+	currentTerm uint64
+	votedFor    string
+	log         []LogEntry
+
+	// Volatile state, all servers.
+	commitIndex uint64
+	lastApplied uint64
+	role        cluster.Role
+	leaderID    string
+
+	// Volatile state, leaders only; reset whenever a node becomes leader.
+	nextIndex  map[string]uint64
+	matchIndex map[string]uint64
+
+	resetElectionCh chan struct{}
+	waiters         map[uint64][]chan struct{} // index -> channels to close on apply
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// New creates a Raft node in the Follower role, loading persistent state
+// from cfg.StatePath if it exists.
+func New(cfg Config) (*Raft, error) {
+	if cfg.ElectionTimeoutMin == 0 {
+		cfg.ElectionTimeoutMin = 150 * time.Millisecond
+	}
+	if cfg.ElectionTimeoutMax == 0 {
+		cfg.ElectionTimeoutMax = 300 * time.Millisecond
+	}
+	if cfg.HeartbeatInterval == 0 {
+		cfg.HeartbeatInterval = 50 * time.Millisecond
+	}
+
+	r := &Raft{
+		cfg:             cfg,
+		log:             []LogEntry{{}}, // sentinel at index 0
+		role:            cluster.Follower,
+		nextIndex:       map[string]uint64{},
+		matchIndex:      map[string]uint64{},
+		resetElectionCh: make(chan struct{}, 1),
+		waiters:         map[uint64][]chan struct{}{},
+		stopCh:          make(chan struct{}),
+	}
+
+	if cfg.StatePath != "" {
+		if err := r.loadState(); err != nil {
+			return nil, fmt.Errorf("consensus: loading persisted state: %w", err)
+		}
+	}
+
+	return r, nil
+}
+
+// Start launches the background election timer. It must be called once,
+// after any HTTP handlers that reference r are registered.
+func (r *Raft) Start() {
+	go r.runElectionTimer()
+}
+
+// Stop terminates the election timer and any in-flight leader loops.
+func (r *Raft) Stop() {
+	r.stopOnce.Do(func() { close(r.stopCh) })
+}
+
+// IsLeader reports whether this node currently believes it is the leader.
+func (r *Raft) IsLeader() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.role == cluster.Leader
+}
+
+// LeaderID returns the base URL of the node this node believes is the
+// current leader, or "" if unknown.
+func (r *Raft) LeaderID() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.leaderID
+}
+
+// Status returns a snapshot suitable for /raft/status.
+func (r *Raft) Status() Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return Status{
+		ID:          r.cfg.Self,
+		Term:        r.currentTerm,
+		Role:        string(r.role),
+		LeaderID:    r.leaderID,
+		LogLength:   len(r.log) - 1, // exclude the sentinel
+		CommitIndex: r.commitIndex,
+		LastApplied: r.lastApplied,
+	}
+}
+
+// ProposeResult is returned by Propose; Done is closed once the entry has
+// been committed (replicated to a majority) and applied.
+type ProposeResult struct {
+	Index uint64
+	Term  uint64
+	Done  <-chan struct{}
+}
+
+// Propose appends cmd to the leader's log and kicks off replication to
+// followers in the background, returning as soon as the entry is durably
+// on the leader's own log. It returns an error if this node is not
+// currently the leader. Callers that need to know the entry reached a
+// majority should wait on the returned Done channel (with their own
+// timeout: Done is only closed on commit or on this node stepping down,
+// see becomeFollowerLocked); async callers can ignore it. Replication
+// itself is quorum-gated, not all-peer-blocking: each peer's reply
+// advances commitIndex independently as it arrives (advanceCommitIndexLocked),
+// so Done fires as soon as a majority has acked even if a minority is
+// down or slow.
+func (r *Raft) Propose(cmd repl.ReplicateRequest) (ProposeResult, error) {
+	r.mu.Lock()
+	if r.role != cluster.Leader {
+		r.mu.Unlock()
+		return ProposeResult{}, fmt.Errorf("consensus: not leader")
+	}
+
+	entry := LogEntry{
+		Term:    r.currentTerm,
+		Index:   uint64(len(r.log)),
+		Command: cmd,
+	}
+	r.log = append(r.log, entry)
+	if err := r.persistLocked(); err != nil {
+		r.log = r.log[:len(r.log)-1]
+		r.mu.Unlock()
+		return ProposeResult{}, fmt.Errorf("consensus: persisting log entry: %w", err)
+	}
+	// A single-node cluster commits immediately.
+	r.matchIndex[r.cfg.Self] = entry.Index
+	done := make(chan struct{})
+	r.waiters[entry.Index] = append(r.waiters[entry.Index], done)
+	r.advanceCommitIndexLocked()
+	r.mu.Unlock()
+
+	// Replication happens in the background: Propose must not block the
+	// caller on a full round trip to every peer (replicateToAllPeers
+	// waits for all of them to finish, including any that are down or
+	// slow up to cfg.Client's timeout), since that would stall every
+	// write behind the single slowest peer regardless of write concern.
+	go r.replicateToAllPeers()
+
+	return ProposeResult{Index: entry.Index, Term: entry.Term, Done: done}, nil
+}
+
+// HandleRequestVote implements the RequestVote RPC handler (§5.2).
+func (r *Raft) HandleRequestVote(args RequestVoteArgs) RequestVoteReply {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if args.Term < r.currentTerm {
+		return RequestVoteReply{Term: r.currentTerm, VoteGranted: false}
+	}
+	if args.Term > r.currentTerm {
+		r.becomeFollowerLocked(args.Term, "")
+	}
+
+	lastIdx, lastTerm := r.lastLogLocked()
+	upToDate := args.LastLogTerm > lastTerm ||
+		(args.LastLogTerm == lastTerm && args.LastLogIndex >= lastIdx)
+
+	if (r.votedFor == "" || r.votedFor == args.CandidateID) && upToDate {
+		r.votedFor = args.CandidateID
+		_ = r.persistLocked()
+		r.resetElection()
+		return RequestVoteReply{Term: r.currentTerm, VoteGranted: true}
+	}
+	return RequestVoteReply{Term: r.currentTerm, VoteGranted: false}
+}
+
+// HandleAppendEntries implements the AppendEntries RPC handler (§5.3).
+func (r *Raft) HandleAppendEntries(args AppendEntriesArgs) AppendEntriesReply {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if args.Term < r.currentTerm {
+		return AppendEntriesReply{Term: r.currentTerm, Success: false}
+	}
+	r.becomeFollowerLocked(args.Term, args.LeaderID)
+	r.resetElection()
+
+	if args.PrevLogIndex > 0 {
+		if args.PrevLogIndex >= uint64(len(r.log)) || r.log[args.PrevLogIndex].Term != args.PrevLogTerm {
+			return AppendEntriesReply{Term: r.currentTerm, Success: false}
+		}
+	}
+
+	for _, entry := range args.Entries {
+		if entry.Index < uint64(len(r.log)) {
+			if r.log[entry.Index].Term == entry.Term {
+				continue
+			}
+			r.log = r.log[:entry.Index]
+		}
+		r.log = append(r.log, entry)
+	}
+	if len(args.Entries) > 0 {
+		_ = r.persistLocked()
+	}
+
+	if args.LeaderCommit > r.commitIndex {
+		r.commitIndex = minUint64(args.LeaderCommit, uint64(len(r.log)-1))
+		r.applyCommittedLocked()
+	}
+
+	return AppendEntriesReply{Term: r.currentTerm, Success: true}
+}
+
+// --- internal helpers ---
+
+func (r *Raft) resetElection() {
+	select {
+	case r.resetElectionCh <- struct{}{}:
+	default:
+	}
+}
+
+func (r *Raft) lastLogLocked() (index, term uint64) {
+	last := r.log[len(r.log)-1]
+	return last.Index, last.Term
+}
+
+// becomeFollowerLocked must be called with r.mu held.
+func (r *Raft) becomeFollowerLocked(term uint64, leaderID string) {
+	wasLeader := r.role == cluster.Leader
+	if term > r.currentTerm {
+		r.currentTerm = term
+		r.votedFor = ""
+		_ = r.persistLocked()
+	}
+	r.role = cluster.Follower
+	if leaderID != "" {
+		r.leaderID = leaderID
+	}
+	if wasLeader {
+		r.abandonWaitersLocked()
+	}
+}
+
+// abandonWaitersLocked closes every pending Propose waiter without
+// applying its entry. Without this, a caller blocked on
+// <-ProposeResult.Done for an entry that never reaches a majority before
+// this node steps down would hang forever, since nothing else ever
+// closes that channel. Callers should re-check IsLeader after Done fires
+// to tell a real commit apart from an abandoned one. Must be called with
+// r.mu held.
+func (r *Raft) abandonWaitersLocked() {
+	for idx, chans := range r.waiters {
+		for _, ch := range chans {
+			close(ch)
+		}
+		delete(r.waiters, idx)
+	}
+}
+
+func (r *Raft) runElectionTimer() {
+	for {
+		timeout := randomDuration(r.cfg.ElectionTimeoutMin, r.cfg.ElectionTimeoutMax)
+		select {
+		case <-time.After(timeout):
+			r.startElection()
+		case <-r.resetElectionCh:
+			continue
+		case <-r.stopCh:
+			return
+		}
+	}
+}
+
+func (r *Raft) startElection() {
+	r.mu.Lock()
+	if r.role == cluster.Leader {
+		r.mu.Unlock()
+		return
+	}
+	r.currentTerm++
+	r.role = Candidate
+	r.votedFor = r.cfg.Self
+	r.leaderID = ""
+	term := r.currentTerm
+	lastIdx, lastTerm := r.lastLogLocked()
+	_ = r.persistLocked()
+	r.mu.Unlock()
+
+	r.cfg.Logger.Printf("consensus: starting election for term %d", term)
+
+	votes := 1 // vote for self
+	var votesMu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, peer := range r.cfg.Peers {
+		wg.Add(1)
+		go func(peer string) {
+			defer wg.Done()
+			reply, err := r.sendRequestVote(peer, RequestVoteArgs{
+				Term:         term,
+				CandidateID:  r.cfg.Self,
+				LastLogIndex: lastIdx,
+				LastLogTerm:  lastTerm,
+			})
+			if err != nil {
+				return
+			}
+			r.mu.Lock()
+			if reply.Term > r.currentTerm {
+				r.becomeFollowerLocked(reply.Term, "")
+			}
+			r.mu.Unlock()
+			if reply.VoteGranted {
+				votesMu.Lock()
+				votes++
+				votesMu.Unlock()
+			}
+		}(peer)
+	}
+	wg.Wait()
+
+	majority := majorityOf(len(r.cfg.Peers))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.role == Candidate && r.currentTerm == term && votes >= majority {
+		r.becomeLeaderLocked()
+	}
+}
+
+// majorityOf returns the number of votes (or acks) needed to win a
+// cluster of numPeers peers plus this node itself. Computing it off
+// numPeers alone (numPeers/2 + 1) undercounts by one for any even-sized
+// cluster (e.g. 3 peers/4 total nodes would only require 2 votes instead
+// of the true majority of 3), which can let two disjoint minorities both
+// believe they've won the same term.
+func majorityOf(numPeers int) int {
+	total := numPeers + 1
+	return total/2 + 1
+}
+
+// becomeLeaderLocked must be called with r.mu held.
+func (r *Raft) becomeLeaderLocked() {
+	r.role = cluster.Leader
+	r.leaderID = r.cfg.Self
+	lastIdx, _ := r.lastLogLocked()
+	r.nextIndex = map[string]uint64{}
+	r.matchIndex = map[string]uint64{}
+	for _, peer := range r.cfg.Peers {
+		r.nextIndex[peer] = lastIdx + 1
+		r.matchIndex[peer] = 0
+	}
+	r.matchIndex[r.cfg.Self] = lastIdx
+	r.cfg.Logger.Printf("consensus: elected leader for term %d", r.currentTerm)
+	go r.leaderHeartbeatLoop(r.currentTerm)
+}
+
+func (r *Raft) leaderHeartbeatLoop(term uint64) {
+	ticker := time.NewTicker(r.cfg.HeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.mu.Lock()
+			stillLeader := r.role == cluster.Leader && r.currentTerm == term
+			r.mu.Unlock()
+			if !stillLeader {
+				return
+			}
+			r.replicateToAllPeers()
+		case <-r.stopCh:
+			return
+		}
+	}
+}
+
+// replicateToAllPeers sends AppendEntries (heartbeat or with pending log
+// entries) to every peer, in parallel, and re-checks commitIndex once
+// replies are in.
+func (r *Raft) replicateToAllPeers() {
+	var wg sync.WaitGroup
+	for _, peer := range r.cfg.Peers {
+		wg.Add(1)
+		go func(peer string) {
+			defer wg.Done()
+			r.replicateToPeer(peer)
+		}(peer)
+	}
+	wg.Wait()
+}
+
+func (r *Raft) replicateToPeer(peer string) {
+	r.mu.Lock()
+	if r.role != cluster.Leader {
+		r.mu.Unlock()
+		return
+	}
+	term := r.currentTerm
+	next := r.nextIndex[peer]
+	if next == 0 {
+		next = uint64(len(r.log))
+	}
+	prevIdx := next - 1
+	prevTerm := r.log[prevIdx].Term
+	var entries []LogEntry
+	if next < uint64(len(r.log)) {
+		entries = append(entries, r.log[next:]...)
+	}
+	args := AppendEntriesArgs{
+		Term:         term,
+		LeaderID:     r.cfg.Self,
+		PrevLogIndex: prevIdx,
+		PrevLogTerm:  prevTerm,
+		Entries:      entries,
+		LeaderCommit: r.commitIndex,
+	}
+	r.mu.Unlock()
+
+	reply, err := r.sendAppendEntries(peer, args)
+	if err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if reply.Term > r.currentTerm {
+		r.becomeFollowerLocked(reply.Term, "")
+		return
+	}
+	if r.role != cluster.Leader || r.currentTerm != term {
+		return
+	}
+	if reply.Success {
+		if len(entries) > 0 {
+			r.matchIndex[peer] = entries[len(entries)-1].Index
+			r.nextIndex[peer] = r.matchIndex[peer] + 1
+		}
+		r.advanceCommitIndexLocked()
+	} else if r.nextIndex[peer] > 1 {
+		r.nextIndex[peer]--
+	}
+}
+
+// advanceCommitIndexLocked recomputes commitIndex as the highest index
+// replicated to a majority of the cluster (leader + peers), then applies
+// any newly committed entries. Must be called with r.mu held.
+func (r *Raft) advanceCommitIndexLocked() {
+	if r.role != cluster.Leader {
+		return
+	}
+	indices := make([]uint64, 0, len(r.cfg.Peers)+1)
+	for _, idx := range r.matchIndex {
+		indices = append(indices, idx)
+	}
+	sortUint64(indices)
+	majorityIdx := indices[(len(indices)-1)/2]
+
+	if majorityIdx > r.commitIndex && r.log[majorityIdx].Term == r.currentTerm {
+		r.commitIndex = majorityIdx
+		r.applyCommittedLocked()
+	}
+}
+
+// applyCommittedLocked applies every log entry between lastApplied and
+// commitIndex, invoking cfg.Apply and waking any Propose callers waiting
+// on that index. Must be called with r.mu held.
+func (r *Raft) applyCommittedLocked() {
+	for r.lastApplied < r.commitIndex {
+		r.lastApplied++
+		entry := r.log[r.lastApplied]
+		if r.cfg.Apply != nil {
+			r.cfg.Apply(entry.Command)
+		}
+		for _, ch := range r.waiters[entry.Index] {
+			close(ch)
+		}
+		delete(r.waiters, entry.Index)
+	}
+}
+
+func (r *Raft) sendRequestVote(peer string, args RequestVoteArgs) (RequestVoteReply, error) {
+	var reply RequestVoteReply
+	err := r.postJSON(peer+"/raft/vote", args, &reply)
+	return reply, err
+}
+
+func (r *Raft) sendAppendEntries(peer string, args AppendEntriesArgs) (AppendEntriesReply, error) {
+	var reply AppendEntriesReply
+	err := r.postJSON(peer+"/raft/append", args, &reply)
+	return reply, err
+}
+
+func (r *Raft) postJSON(url string, body, out interface{}) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("consensus: marshal request to %s: %w", url, err)
+	}
+	resp, err := r.cfg.Client.Post(url, "application/json", jsonReader(b))
+	if err != nil {
+		return fmt.Errorf("consensus: post to %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("consensus: non-200 from %s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// persistLocked writes currentTerm, votedFor and the log to cfg.StatePath.
+// It must be called with r.mu held. Writing goes through a temp file and
+// rename so a crash mid-write can't corrupt the existing state.
+func (r *Raft) persistLocked() error {
+	if r.cfg.StatePath == "" {
+		return nil
+	}
+	state := persistentState{
+		CurrentTerm: r.currentTerm,
+		VotedFor:    r.votedFor,
+		Log:         r.log,
+	}
+	b, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("consensus: marshal state: %w", err)
+	}
+	tmp := r.cfg.StatePath + ".tmp"
+	if err := os.MkdirAll(filepath.Dir(r.cfg.StatePath), 0o755); err != nil {
+		return fmt.Errorf("consensus: creating state dir: %w", err)
+	}
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return fmt.Errorf("consensus: writing state file: %w", err)
+	}
+	return os.Rename(tmp, r.cfg.StatePath)
+}
+
+func (r *Raft) loadState() error {
+	b, err := os.ReadFile(r.cfg.StatePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading state file: %w", err)
+	}
+	var state persistentState
+	if err := json.Unmarshal(b, &state); err != nil {
+		return fmt.Errorf("parsing state file: %w", err)
+	}
+	r.currentTerm = state.CurrentTerm
+	r.votedFor = state.VotedFor
+	if len(state.Log) > 0 {
+		r.log = state.Log
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+// internal/consensus/raft_test.go
+package consensus
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Chinzzii/leader-replication-go/internal/cluster"
+)
+
+func TestMajorityOf(t *testing.T) {
+	cases := []struct {
+		peers int
+		want  int
+	}{
+		{0, 1}, // single-node cluster: just itself
+		{1, 2}, // 2 total nodes: both must agree
+		{2, 2}, // 3 total nodes: 2 of 3
+		{3, 3}, // 4 total nodes: 3 of 4 (not 2 - this was the bug)
+		{4, 3}, // 5 total nodes: 3 of 5
+		{5, 4}, // 6 total nodes: 4 of 6 (not 3)
+	}
+	for _, c := range cases {
+		if got := majorityOf(c.peers); got != c.want {
+			t.Errorf("majorityOf(%d) = %d, want %d", c.peers, got, c.want)
+		}
+	}
+}
+
+func TestPersistAndLoadStateRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	statePath := filepath.Join(dir, "node.raft.json")
+
+	r, err := New(Config{
+		NodeID:    "node-1",
+		Self:      "http://localhost:9001",
+		Logger:    noopLogger{},
+		StatePath: statePath,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	r.mu.Lock()
+	r.currentTerm = 7
+	r.votedFor = "http://localhost:9002"
+	r.role = Candidate
+	if err := r.persistLocked(); err != nil {
+		r.mu.Unlock()
+		t.Fatalf("persistLocked: %v", err)
+	}
+	r.mu.Unlock()
+
+	reloaded, err := New(Config{
+		NodeID:    "node-1",
+		Self:      "http://localhost:9001",
+		Logger:    noopLogger{},
+		StatePath: statePath,
+	})
+	if err != nil {
+		t.Fatalf("New (reload): %v", err)
+	}
+
+	if reloaded.currentTerm != 7 {
+		t.Errorf("currentTerm = %d, want 7", reloaded.currentTerm)
+	}
+	if reloaded.votedFor != "http://localhost:9002" {
+		t.Errorf("votedFor = %q, want %q", reloaded.votedFor, "http://localhost:9002")
+	}
+	// A freshly loaded node always starts as Follower regardless of the
+	// role it held (here Candidate) when its state was last persisted.
+	if reloaded.role != cluster.Follower {
+		t.Errorf("role = %q, want follower", reloaded.role)
+	}
+}
+
+func TestBecomeFollowerLockedAbandonsWaitersOnStepDown(t *testing.T) {
+	r, err := New(Config{
+		NodeID: "node-1",
+		Self:   "http://localhost:9001",
+		Logger: noopLogger{},
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	r.mu.Lock()
+	r.role = cluster.Leader
+	r.currentTerm = 3
+	done := make(chan struct{})
+	r.waiters[1] = append(r.waiters[1], done)
+	r.becomeFollowerLocked(4, "http://localhost:9002")
+	r.mu.Unlock()
+
+	select {
+	case <-done:
+	default:
+		t.Fatal("expected waiter to be closed once this node stepped down, but it's still blocked")
+	}
+	if len(r.waiters) != 0 {
+		t.Errorf("waiters = %v, want empty map after step-down", r.waiters)
+	}
+}
+
+type noopLogger struct{}
+
+func (noopLogger) Printf(format string, v ...interface{}) {}
@@ -0,0 +1,31 @@
+// internal/consensus/util.go
+package consensus
+
+import (
+	"bytes"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+func randomDuration(min, max time.Duration) time.Duration {
+	if max <= min {
+		return min
+	}
+	return min + time.Duration(rand.Int63n(int64(max-min)))
+}
+
+func minUint64(a, b uint64) uint64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func sortUint64(s []uint64) {
+	sort.Slice(s, func(i, j int) bool { return s[i] < s[j] })
+}
+
+func jsonReader(b []byte) *bytes.Reader {
+	return bytes.NewReader(b)
+}
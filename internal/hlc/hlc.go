@@ -0,0 +1,159 @@
+// internal/hlc/hlc.go
+package hlc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Timestamp is a Hybrid Logical Clock value: a physical component (wall
+// clock nanoseconds) paired with a logical counter that breaks ties
+// between events whose physical component coincides. Timestamps are
+// totally ordered by (Physical, Logical), which is what lets Clock give
+// causally consistent Last-Write-Wins ordering across nodes whose wall
+// clocks aren't perfectly synchronized.
+type Timestamp struct {
+	Physical int64  `json:"-"` // wall-clock nanoseconds
+	Logical  uint32 `json:"-"`
+}
+
+// String renders t as "physical.logical", the wire format ParseTimestamp
+// expects back.
+func (t Timestamp) String() string {
+	return fmt.Sprintf("%d.%d", t.Physical, t.Logical)
+}
+
+// ParseTimestamp parses the "physical.logical" format produced by String.
+func ParseTimestamp(s string) (Timestamp, error) {
+	phys, logical, ok := strings.Cut(s, ".")
+	if !ok {
+		return Timestamp{}, fmt.Errorf("hlc: invalid timestamp %q, want \"physical.logical\"", s)
+	}
+	p, err := strconv.ParseInt(phys, 10, 64)
+	if err != nil {
+		return Timestamp{}, fmt.Errorf("hlc: invalid physical component in %q: %w", s, err)
+	}
+	l, err := strconv.ParseUint(logical, 10, 32)
+	if err != nil {
+		return Timestamp{}, fmt.Errorf("hlc: invalid logical component in %q: %w", s, err)
+	}
+	return Timestamp{Physical: p, Logical: uint32(l)}, nil
+}
+
+// MarshalJSON encodes t as the quoted "physical.logical" string, so it
+// drops into any JSON payload a time.Time previously occupied.
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + t.String() + `"`), nil
+}
+
+// UnmarshalJSON decodes the quoted "physical.logical" string produced by
+// MarshalJSON.
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	parsed, err := ParseTimestamp(s)
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// Before reports whether t happened causally before other.
+func (t Timestamp) Before(other Timestamp) bool {
+	return t.Physical < other.Physical || (t.Physical == other.Physical && t.Logical < other.Logical)
+}
+
+// After reports whether t happened causally after other.
+func (t Timestamp) After(other Timestamp) bool {
+	return other.Before(t)
+}
+
+// Equal reports whether t and other are the same HLC value.
+func (t Timestamp) Equal(other Timestamp) bool {
+	return t == other
+}
+
+// DefaultMaxDrift bounds how far a remote timestamp's physical component
+// may run ahead of this node's own wall clock before Update rejects it.
+// 500ms is generous enough to absorb ordinary NTP skew while still
+// catching a misconfigured or jumped clock before it reorders history.
+const DefaultMaxDrift = 500 * time.Millisecond
+
+// Clock is a single node's Hybrid Logical Clock. The zero value is not
+// usable; construct one with New.
+type Clock struct {
+	mu       sync.Mutex
+	last     Timestamp
+	maxDrift time.Duration
+	nowFn    func() time.Time
+}
+
+// New constructs a Clock that rejects remote timestamps whose physical
+// component is more than maxDrift ahead of this node's wall clock. A
+// maxDrift of zero or less falls back to DefaultMaxDrift.
+func New(maxDrift time.Duration) *Clock {
+	if maxDrift <= 0 {
+		maxDrift = DefaultMaxDrift
+	}
+	return &Clock{maxDrift: maxDrift, nowFn: time.Now}
+}
+
+// Now produces the Timestamp for a local event: physical advances to the
+// wall clock unless the clock hasn't moved since the last event, in
+// which case the logical counter breaks the tie.
+func (c *Clock) Now() Timestamp {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	wall := c.nowFn().UnixNano()
+	if wall > c.last.Physical {
+		c.last = Timestamp{Physical: wall, Logical: 0}
+	} else {
+		c.last.Logical++
+	}
+	return c.last
+}
+
+// Update advances the clock on receipt of a remote Timestamp, producing
+// a value strictly greater than both the local clock and remote, per the
+// HLC algorithm. It rejects remote timestamps whose physical component
+// is more than maxDrift ahead of this node's own wall clock, since
+// accepting one would let a single misbehaving node's clock silently
+// reorder every future write.
+func (c *Clock) Update(remote Timestamp) (Timestamp, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	wall := c.nowFn().UnixNano()
+	if drift := remote.Physical - wall; drift > c.maxDrift.Nanoseconds() {
+		return Timestamp{}, fmt.Errorf("hlc: remote timestamp %s is %s ahead of local clock, exceeds max drift %s", remote, time.Duration(drift), c.maxDrift)
+	}
+
+	phys := wall
+	if c.last.Physical > phys {
+		phys = c.last.Physical
+	}
+	if remote.Physical > phys {
+		phys = remote.Physical
+	}
+
+	var logical uint32
+	switch phys {
+	case c.last.Physical:
+		logical = c.last.Logical
+		if remote.Physical == phys && remote.Logical > logical {
+			logical = remote.Logical
+		}
+		logical++
+	case remote.Physical:
+		logical = remote.Logical + 1
+	default:
+		logical = 0
+	}
+
+	c.last = Timestamp{Physical: phys, Logical: logical}
+	return c.last, nil
+}
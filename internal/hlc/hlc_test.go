@@ -0,0 +1,89 @@
+// internal/hlc/hlc_test.go
+package hlc
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestClockNowAdvancesOrTicksLogical(t *testing.T) {
+	wall := int64(1000)
+	c := New(time.Second)
+	c.nowFn = func() time.Time { return time.Unix(0, wall) }
+
+	first := c.Now()
+	if first.Physical != wall || first.Logical != 0 {
+		t.Fatalf("first Now() = %+v, want {Physical:%d Logical:0}", first, wall)
+	}
+
+	// Wall clock hasn't moved: the logical counter should break the tie.
+	second := c.Now()
+	if second.Physical != wall || second.Logical != 1 {
+		t.Fatalf("second Now() = %+v, want {Physical:%d Logical:1}", second, wall)
+	}
+
+	// Wall clock advances: physical should jump forward and logical reset.
+	wall = 2000
+	third := c.Now()
+	if third.Physical != wall || third.Logical != 0 {
+		t.Fatalf("third Now() = %+v, want {Physical:%d Logical:0}", third, wall)
+	}
+}
+
+func TestClockUpdateAcceptsValidRemote(t *testing.T) {
+	wall := int64(1000)
+	c := New(time.Second)
+	c.nowFn = func() time.Time { return time.Unix(0, wall) }
+
+	remote := Timestamp{Physical: wall + 500, Logical: 2}
+	got, err := c.Update(remote)
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if !got.After(remote) {
+		t.Errorf("Update result %+v is not after remote %+v", got, remote)
+	}
+}
+
+func TestClockUpdateRejectsExcessiveDrift(t *testing.T) {
+	wall := int64(1000)
+	c := New(time.Millisecond) // tiny max drift
+	c.nowFn = func() time.Time { return time.Unix(0, wall) }
+
+	remote := Timestamp{Physical: wall + int64(time.Second), Logical: 0}
+	if _, err := c.Update(remote); err == nil {
+		t.Fatal("Update: got nil error for a remote timestamp far ahead of local wall clock, want error")
+	}
+}
+
+func TestTimestampJSONRoundTrip(t *testing.T) {
+	want := Timestamp{Physical: 1234567890, Logical: 42}
+
+	b, err := json.Marshal(want)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var got Timestamp
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if got != want {
+		t.Errorf("round trip = %+v, want %+v", got, want)
+	}
+}
+
+func TestTimestampOrdering(t *testing.T) {
+	earlier := Timestamp{Physical: 1, Logical: 5}
+	later := Timestamp{Physical: 1, Logical: 6}
+	if !earlier.Before(later) {
+		t.Errorf("%+v.Before(%+v) = false, want true", earlier, later)
+	}
+	if !later.After(earlier) {
+		t.Errorf("%+v.After(%+v) = false, want true", later, earlier)
+	}
+	if !earlier.Equal(earlier) {
+		t.Errorf("%+v.Equal(itself) = false, want true", earlier)
+	}
+}
@@ -0,0 +1,39 @@
+// internal/repl/grpc_server.go
+package repl
+
+import (
+	"github.com/Chinzzii/leader-replication-go/internal/repl/pb"
+)
+
+// GRPCReplicationServer implements pb.ReplicationServer on the follower
+// side of the gRPC transport: it applies every entry it receives via
+// Apply and pipelines an ack back for each one, independent of the
+// others, rather than acking a whole batch at once.
+type GRPCReplicationServer struct {
+	pb.UnimplementedReplicationServer
+
+	// Apply is called once per received entry, in order. Its error (if
+	// any) is reported back to the leader in that entry's ack.
+	Apply func(ReplicateRequest) error
+}
+
+// StreamReplicate implements pb.ReplicationServer.
+func (s *GRPCReplicationServer) StreamReplicate(stream pb.Replication_StreamReplicateServer) error {
+	for {
+		batch, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		for _, e := range batch.Entries {
+			req := fromPBEntry(e)
+			ack := &pb.ReplicateAck{ReqId: req.ReqID, Ok: true}
+			if err := s.Apply(req); err != nil {
+				ack.Ok = false
+				ack.Error = err.Error()
+			}
+			if err := stream.Send(ack); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,37 @@
+// internal/repl/http_transport.go
+package repl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// HTTPTransport is the original wire format: one JSON POST to /replicate
+// per entry, no connection reuse beyond what http.Client already pools.
+type HTTPTransport struct {
+	client *http.Client
+}
+
+// NewHTTPTransport builds an HTTPTransport that sends requests with client.
+func NewHTTPTransport(client *http.Client) *HTTPTransport {
+	return &HTTPTransport{client: client}
+}
+
+// Replicate posts each entry in batch to peer in turn, stopping at the
+// first error. ctx is not consulted: each post is bounded only by the
+// http.Client's own timeout.
+func (t *HTTPTransport) Replicate(ctx context.Context, peer string, batch []ReplicateRequest) error {
+	for _, req := range batch {
+		if err := PostReplicate(t.client, peer, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stream is unsupported over plain HTTP/JSON; callers that need a
+// long-lived connection should use the gRPC transport instead.
+func (t *HTTPTransport) Stream(ctx context.Context, peer string) (Stream, error) {
+	return nil, fmt.Errorf("http transport: streaming is not supported, use -transport=grpc")
+}
@@ -6,16 +6,20 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"time"
+
+	"github.com/Chinzzii/leader-replication-go/internal/hlc"
 )
 
 // ReplicateRequest is the payload sent from a leader to a follower
-// to replicate a single write operation.
+// to replicate a single write operation. TS is an HLC timestamp (see
+// internal/hlc), not a wall-clock time, so a follower can fold it into
+// its own clock before applying the write.
 type ReplicateRequest struct {
-	Key   string    `json:"key"`
-	Value string    `json:"value"`
-	TS    time.Time `json:"ts"`
-	ReqID string    `json:"req_id"` // ReqID for tracing/logging
+	Key   string        `json:"key"`
+	Value string        `json:"value"`
+	TS    hlc.Timestamp `json:"ts"`
+	ReqID string        `json:"req_id"` // ReqID for tracing/logging
+	LSN   uint64        `json:"lsn"`    // WAL log sequence number assigned by the leader
 }
 
 // ReplicateResponse is the simple ACK response from a follower.
@@ -52,3 +56,35 @@ func PostReplicate(client *http.Client, baseURL string, body ReplicateRequest) e
 
 	return nil
 }
+
+// FetchSince calls GET /replicate/since?lsn=N on baseURL and streams the
+// response back to the caller one write at a time via onRecord, for a
+// follower that was partitioned or restarted to catch up without the
+// leader having to retry every missed write individually. The response
+// body is newline-delimited JSON, so records are applied as they arrive
+// instead of waiting for the whole catch-up batch to download.
+func FetchSince(client *http.Client, baseURL string, lsn uint64, onRecord func(ReplicateRequest) error) error {
+	url := fmt.Sprintf("%s/replicate/since?lsn=%d", baseURL, lsn)
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return fmt.Errorf("http get %s failed: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("non-200 status from %s: %s", url, resp.Status)
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	for dec.More() {
+		var rec ReplicateRequest
+		if err := dec.Decode(&rec); err != nil {
+			return fmt.Errorf("decoding catch-up record from %s: %w", url, err)
+		}
+		if err := onRecord(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
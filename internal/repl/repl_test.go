@@ -0,0 +1,120 @@
+// internal/repl/repl_test.go
+package repl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Chinzzii/leader-replication-go/internal/hlc"
+)
+
+func TestPostReplicate(t *testing.T) {
+	var got ReplicateRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/replicate" {
+			t.Errorf("path = %q, want /replicate", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Fatalf("decoding request body: %v", err)
+		}
+		json.NewEncoder(w).Encode(ReplicateResponse{Status: "ok"})
+	}))
+	defer srv.Close()
+
+	want := ReplicateRequest{Key: "a", Value: "1", TS: hlc.Timestamp{Physical: 1}, ReqID: "r1", LSN: 1}
+	if err := PostReplicate(srv.Client(), srv.URL, want); err != nil {
+		t.Fatalf("PostReplicate: %v", err)
+	}
+	if got != want {
+		t.Errorf("server received %+v, want %+v", got, want)
+	}
+}
+
+func TestPostReplicateNon200IsError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	if err := PostReplicate(srv.Client(), srv.URL, ReplicateRequest{}); err == nil {
+		t.Fatal("PostReplicate: got nil error for a 500 response, want error")
+	}
+}
+
+func TestFetchSince(t *testing.T) {
+	records := []ReplicateRequest{
+		{Key: "a", Value: "1", TS: hlc.Timestamp{Physical: 1}, ReqID: "r1", LSN: 1},
+		{Key: "b", Value: "2", TS: hlc.Timestamp{Physical: 2}, ReqID: "r2", LSN: 2},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("lsn"); got != "0" {
+			t.Errorf("lsn query param = %q, want \"0\"", got)
+		}
+		enc := json.NewEncoder(w)
+		for _, rec := range records {
+			if err := enc.Encode(rec); err != nil {
+				t.Fatalf("encoding record: %v", err)
+			}
+		}
+	}))
+	defer srv.Close()
+
+	var got []ReplicateRequest
+	err := FetchSince(srv.Client(), srv.URL, 0, func(rec ReplicateRequest) error {
+		got = append(got, rec)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("FetchSince: %v", err)
+	}
+	if len(got) != len(records) {
+		t.Fatalf("got %d records, want %d", len(got), len(records))
+	}
+	for i := range records {
+		if got[i] != records[i] {
+			t.Errorf("record %d = %+v, want %+v", i, got[i], records[i])
+		}
+	}
+}
+
+func TestHTTPTransportReplicateStopsAtFirstError(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(ReplicateResponse{Status: "ok"})
+	}))
+	defer srv.Close()
+
+	transport := NewHTTPTransport(srv.Client())
+	batch := []ReplicateRequest{
+		{Key: "a", LSN: 1},
+		{Key: "b", LSN: 2},
+		{Key: "c", LSN: 3},
+	}
+	err := transport.Replicate(context.Background(), srv.URL, batch)
+	if err == nil {
+		t.Fatal("Replicate: got nil error, want error from the second entry's 500")
+	}
+	if calls != 2 {
+		t.Errorf("server received %d calls, want 2 (should stop at first error)", calls)
+	}
+}
+
+func TestHTTPTransportStreamUnsupported(t *testing.T) {
+	transport := NewHTTPTransport(http.DefaultClient)
+	_, err := transport.Stream(context.Background(), "http://example.com")
+	if err == nil {
+		t.Fatal("Stream: got nil error, want \"not supported\" error")
+	}
+	if fmt.Sprint(err) == "" {
+		t.Fatal("Stream: error message is empty")
+	}
+}
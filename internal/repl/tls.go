@@ -0,0 +1,46 @@
+// internal/repl/tls.go
+package repl
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// LoadMTLSConfig builds a *tls.Config for cluster-internal traffic from a
+// per-node certificate/key pair and a CA used to verify peers. This keeps
+// cluster traffic authenticated separately from whatever TLS (if any) the
+// client-facing API uses. If none of certFile, keyFile and caFile is set,
+// LoadMTLSConfig returns nil, nil so callers fall back to plaintext;
+// setting only some of them is an error.
+func LoadMTLSConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
+	if certFile == "" && keyFile == "" && caFile == "" {
+		return nil, nil
+	}
+	if certFile == "" || keyFile == "" || caFile == "" {
+		return nil, fmt.Errorf("repl: mTLS requires cert, key and ca to all be set")
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("repl: loading node cert/key: %w", err)
+	}
+
+	caPEM, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("repl: reading CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("repl: no certificates found in %s", caFile)
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      pool,
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
@@ -0,0 +1,30 @@
+// internal/repl/transport.go
+package repl
+
+import "context"
+
+// Stream is a long-lived, bidirectional connection to a single peer,
+// used by transports (like the gRPC one) that keep a connection open
+// across many writes instead of dialing per-request.
+type Stream interface {
+	// Send enqueues req for the peer. It may return before the peer has
+	// acknowledged req.
+	Send(req ReplicateRequest) error
+	// Recv blocks until the peer acknowledges the next sent entry.
+	Recv() (ReplicateResponse, error)
+	// Close tears down the underlying connection.
+	Close() error
+}
+
+// Transport abstracts how a leader gets a batch of writes to a follower,
+// so the replication logic in internal/api doesn't care whether it's
+// talking plain HTTP/JSON or a pipelined gRPC stream.
+type Transport interface {
+	// Replicate sends batch to peer and returns once every entry in it
+	// has been acknowledged (or ctx is done, or an entry is rejected).
+	Replicate(ctx context.Context, peer string, batch []ReplicateRequest) error
+	// Stream opens (or reuses) a long-lived connection to peer for
+	// transports that support one. Implementations that don't may return
+	// an error.
+	Stream(ctx context.Context, peer string) (Stream, error)
+}
@@ -2,16 +2,21 @@
 package store
 
 import (
+	"fmt"
 	"sync"
-	"time"
+
+	"github.com/Chinzzii/leader-replication-go/internal/hlc"
+	"github.com/Chinzzii/leader-replication-go/internal/wal"
 )
 
 // Entry represents a single key-value pair with a timestamp.
-// The timestamp is used for conflict resolution (Last Write Wins).
+// The timestamp is an HLC value rather than a wall-clock time, so
+// conflict resolution (Last Write Wins) stays causally correct even
+// across nodes whose clocks have drifted.
 type Entry struct {
-	Key   string    `json:"key"`
-	Value string    `json:"value"`
-	TS    time.Time `json:"ts"` // TS is the timestamp of the write
+	Key   string        `json:"key"`
+	Value string        `json:"value"`
+	TS    hlc.Timestamp `json:"ts"` // TS is the HLC timestamp of the write
 }
 
 // KV provides a thread-safe, in-memory key-value store.
@@ -21,12 +26,27 @@ type KV struct {
 	data map[string]Entry // data stores all key-value entries
 }
 
-// New creates and initializes a new KV store.
-func New() *KV {
-	return &KV{
+// New creates and initializes a new KV store. If w is non-nil, the store
+// is rebuilt from the write-ahead log before New returns, so a restarted
+// node recovers whatever it had written before it crashed or was stopped.
+func New(w *wal.WAL) (*KV, error) {
+	kv := &KV{
 		data: make(map[string]Entry),
 		// The mu (RWMutex) is usable at its zero value.
 	}
+
+	if w == nil {
+		return kv, nil
+	}
+
+	err := w.Replay(func(rec wal.Record) error {
+		kv.Upsert(Entry{Key: rec.Key, Value: rec.Value, TS: rec.TS})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("store: replaying WAL: %w", err)
+	}
+	return kv, nil
 }
 
 // Upsert adds or updates an entry in the store.
@@ -0,0 +1,431 @@
+// internal/wal/wal.go
+package wal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Chinzzii/leader-replication-go/internal/hlc"
+)
+
+// Record is a single accepted write, persisted before it is applied to
+// store.KV so the store can be rebuilt after a crash. LSN is a
+// monotonically increasing log sequence number assigned by the leader;
+// followers persist the LSN they were given so their own WAL stays in
+// the same order as the leader's. TS is an HLC timestamp rather than a
+// wall-clock time; see internal/hlc.
+type Record struct {
+	ReqID string        `json:"req_id"`
+	Key   string        `json:"key"`
+	Value string        `json:"value"`
+	TS    hlc.Timestamp `json:"ts"`
+	LSN   uint64        `json:"lsn"`
+}
+
+// segmentPrefix and the zero-padded index suffix give us a lexically
+// (and numerically) sorted segment file name, e.g. "segment-0000000001.wal".
+const segmentPrefix = "segment-"
+
+// defaultMaxSegmentBytes bounds how large a single segment file grows
+// before WAL rotates to a new one.
+const defaultMaxSegmentBytes = 8 * 1024 * 1024
+
+// WAL is an append-only, length-prefixed, CRC-checked write-ahead log
+// split across rotating segment files on disk.
+type WAL struct {
+	mu sync.Mutex
+
+	dir             string
+	maxSegmentBytes int64
+
+	segments []string // paths of every segment, oldest first
+	cur      *os.File
+	curBytes int64
+	curIndex int
+	lastLSN  uint64
+
+	// appliedThrough is the highest LSN N such that every LSN <= N has
+	// been marked applied, with no gaps; pending holds LSNs that arrived ahead
+	// of a gap (e.g. replicated out of order) until it closes. Unlike
+	// lastLSN, this never jumps ahead of a missing entry, which is what
+	// makes it safe to gate a linearizable read on.
+	appliedThrough uint64
+	pending        map[uint64]struct{}
+}
+
+// Open opens (creating if necessary) the WAL rooted at dir. It does not
+// replay any records itself; callers that need to rebuild in-memory
+// state should call Replay once Open returns.
+func Open(dir string) (*WAL, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("wal: creating dir %s: %w", dir, err)
+	}
+
+	w := &WAL{dir: dir, maxSegmentBytes: defaultMaxSegmentBytes}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("wal: reading dir %s: %w", dir, err)
+	}
+	for _, e := range entries {
+		if !e.IsDir() {
+			w.segments = append(w.segments, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(w.segments)
+
+	if len(w.segments) == 0 {
+		if err := w.rotateLocked(); err != nil {
+			return nil, err
+		}
+	} else {
+		last := w.segments[len(w.segments)-1]
+		f, err := os.OpenFile(last, os.O_APPEND|os.O_RDWR, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("wal: opening segment %s: %w", last, err)
+		}
+		info, err := f.Stat()
+		if err != nil {
+			return nil, fmt.Errorf("wal: stat segment %s: %w", last, err)
+		}
+		w.cur = f
+		w.curBytes = info.Size()
+		fmt.Sscanf(filepath.Base(last), segmentPrefix+"%d.wal", &w.curIndex)
+
+		// Scan every existing segment once to recover lastLSN and
+		// appliedThrough.
+		if err := w.replayLocked(func(r Record) error {
+			if r.LSN > w.lastLSN {
+				w.lastLSN = r.LSN
+			}
+			w.recordAppliedLocked(r.LSN)
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return w, nil
+}
+
+// NextLSN atomically hands out the next log sequence number. Only the
+// current leader should call this; followers persist the LSN a replicated
+// entry already carries.
+func (w *WAL) NextLSN() uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lastLSN++
+	return w.lastLSN
+}
+
+// LastLSN returns the highest LSN seen so far, without allocating a new
+// one. Used to report replication lag on /status.
+func (w *WAL) LastLSN() uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lastLSN
+}
+
+// AppliedThrough returns the highest LSN N such that every LSN <= N has
+// been marked applied via MarkApplied, with no gaps. Unlike LastLSN, it
+// never jumps ahead of a missing entry, which is what makes it safe to
+// gate a linearizable read on: a follower whose replicated writes arrive
+// out of order may have appended LSN 7 before LSN 6, but AppliedThrough
+// only advances to 7 once 6 has also landed.
+func (w *WAL) AppliedThrough() uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.appliedThrough
+}
+
+// MarkApplied records that lsn's effect is now visible in the in-memory
+// store, advancing AppliedThrough past it and any previously-pending
+// LSNs that are now contiguous. Append itself only guarantees lsn is
+// durably on disk, not yet visible to reads, so callers that serve reads
+// out of the store (e.g. ApplyReplicated) must call MarkApplied only
+// after their store.Upsert has returned — otherwise a linearizable read
+// could observe the watermark before the value it's waiting for.
+func (w *WAL) MarkApplied(lsn uint64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.recordAppliedLocked(lsn)
+}
+
+// recordAppliedLocked marks lsn as applied, advancing appliedThrough past
+// it and any previously-pending LSNs that are now contiguous. Must be
+// called with w.mu held.
+func (w *WAL) recordAppliedLocked(lsn uint64) {
+	if lsn <= w.appliedThrough {
+		return
+	}
+	if lsn != w.appliedThrough+1 {
+		if w.pending == nil {
+			w.pending = map[uint64]struct{}{}
+		}
+		w.pending[lsn] = struct{}{}
+		return
+	}
+	w.appliedThrough = lsn
+	for {
+		next := w.appliedThrough + 1
+		if _, ok := w.pending[next]; !ok {
+			break
+		}
+		delete(w.pending, next)
+		w.appliedThrough = next
+	}
+}
+
+// Append persists rec to the current segment, rotating to a new segment
+// first if the current one has grown past maxSegmentBytes.
+func (w *WAL) Append(rec Record) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.curBytes >= w.maxSegmentBytes {
+		if err := w.rotateLocked(); err != nil {
+			return err
+		}
+	}
+
+	payload, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("wal: marshaling record: %w", err)
+	}
+
+	n, err := writeFramedRecord(w.cur, payload)
+	if err != nil {
+		return fmt.Errorf("wal: writing record: %w", err)
+	}
+	if err := w.cur.Sync(); err != nil {
+		return fmt.Errorf("wal: fsync: %w", err)
+	}
+	w.curBytes += int64(n)
+	if rec.LSN > w.lastLSN {
+		w.lastLSN = rec.LSN
+	}
+	return nil
+}
+
+// Replay reads every record in every segment, oldest first, calling fn
+// for each one in order. It's used by store.New to rebuild the in-memory
+// KV on startup.
+func (w *WAL) Replay(fn func(Record) error) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.replayLocked(fn)
+}
+
+// Since returns every record with an LSN strictly greater than lsn, in
+// order, across all segments. It backs the /replicate/since?lsn=N
+// catch-up endpoint.
+func (w *WAL) Since(lsn uint64) ([]Record, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var out []Record
+	err := w.replayLocked(func(r Record) error {
+		if r.LSN > lsn {
+			out = append(out, r)
+		}
+		return nil
+	})
+	return out, err
+}
+
+// Compact collapses multiple records for the same key down to the one
+// with the latest TS, rewriting the whole WAL into a single fresh
+// segment. It bounds disk growth for keys that are written often.
+func (w *WAL) Compact() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	latest := make(map[string]Record)
+	if err := w.replayLocked(func(r Record) error {
+		if cur, ok := latest[r.Key]; !ok || r.TS.After(cur.TS) {
+			latest[r.Key] = r
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("wal: compacting: reading records: %w", err)
+	}
+
+	ordered := make([]Record, 0, len(latest))
+	for _, r := range latest {
+		ordered = append(ordered, r)
+	}
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].LSN < ordered[j].LSN })
+
+	oldSegments := w.segments
+	if w.cur != nil {
+		w.cur.Close()
+		w.cur = nil
+	}
+	w.segments = nil
+	w.curBytes = 0
+	// curIndex is deliberately left as-is (not reset to 0): rotateLocked
+	// names the next segment curIndex+1, so resetting it here would hand
+	// the compacted segment the very same filename the just-closed
+	// segment had. Since rotateLocked opens with O_APPEND and no
+	// truncation, that would silently append the compacted records on
+	// top of the old file's bytes instead of replacing them, and the old
+	// path would then match the new one and never get cleaned up below —
+	// compaction would grow the file forever instead of bounding it.
+	if err := w.rotateLocked(); err != nil {
+		return fmt.Errorf("wal: compacting: starting fresh segment: %w", err)
+	}
+	for _, r := range ordered {
+		payload, err := json.Marshal(r)
+		if err != nil {
+			return fmt.Errorf("wal: compacting: marshaling record: %w", err)
+		}
+		n, err := writeFramedRecord(w.cur, payload)
+		if err != nil {
+			return fmt.Errorf("wal: compacting: writing record: %w", err)
+		}
+		w.curBytes += int64(n)
+	}
+	if err := w.cur.Sync(); err != nil {
+		return fmt.Errorf("wal: compacting: fsync: %w", err)
+	}
+
+	for _, seg := range oldSegments {
+		if seg == w.segments[0] {
+			continue
+		}
+		_ = os.Remove(seg)
+	}
+	return nil
+}
+
+// StartCompactionLoop runs Compact on interval until the returned stop
+// function is called.
+func (w *WAL) StartCompactionLoop(interval time.Duration, logf func(format string, v ...interface{})) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := w.Compact(); err != nil && logf != nil {
+					logf("wal: compaction failed: %v", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// Close flushes and closes the current segment file.
+func (w *WAL) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.cur == nil {
+		return nil
+	}
+	return w.cur.Close()
+}
+
+// --- internal helpers ---
+
+// rotateLocked closes the current segment (if any) and opens a new,
+// empty one. Must be called with w.mu held.
+func (w *WAL) rotateLocked() error {
+	if w.cur != nil {
+		if err := w.cur.Close(); err != nil {
+			return fmt.Errorf("wal: closing segment: %w", err)
+		}
+	}
+	w.curIndex++
+	path := filepath.Join(w.dir, fmt.Sprintf("%s%010d.wal", segmentPrefix, w.curIndex))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0o644)
+	if err != nil {
+		return fmt.Errorf("wal: creating segment %s: %w", path, err)
+	}
+	w.cur = f
+	w.curBytes = 0
+	w.segments = append(w.segments, path)
+	return nil
+}
+
+// replayLocked reads every record from every segment, in order. Must be
+// called with w.mu held.
+func (w *WAL) replayLocked(fn func(Record) error) error {
+	for _, path := range w.segments {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("wal: opening segment %s: %w", path, err)
+		}
+		err = readFramedRecords(f, func(payload []byte) error {
+			var rec Record
+			if err := json.Unmarshal(payload, &rec); err != nil {
+				return fmt.Errorf("wal: decoding record in %s: %w", path, err)
+			}
+			return fn(rec)
+		})
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFramedRecord writes a single [4-byte length][4-byte CRC32][payload]
+// frame and returns the total number of bytes written.
+func writeFramedRecord(w io.Writer, payload []byte) (int, error) {
+	header := make([]byte, 8)
+	binary.BigEndian.PutUint32(header[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(header[4:8], crc32.ChecksumIEEE(payload))
+	if _, err := w.Write(header); err != nil {
+		return 0, err
+	}
+	if _, err := w.Write(payload); err != nil {
+		return 0, err
+	}
+	return len(header) + len(payload), nil
+}
+
+// readFramedRecords reads every [length][crc32][payload] frame from r,
+// verifying the checksum, until EOF. A truncated trailing frame (e.g. from
+// a crash mid-write) is treated as the end of the log rather than an error.
+func readFramedRecords(r io.Reader, fn func(payload []byte) error) error {
+	br := bufio.NewReader(r)
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(br, header); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return nil
+			}
+			return fmt.Errorf("wal: reading frame header: %w", err)
+		}
+		length := binary.BigEndian.Uint32(header[0:4])
+		wantCRC := binary.BigEndian.Uint32(header[4:8])
+
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(br, payload); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return nil // truncated trailing write, stop here
+			}
+			return fmt.Errorf("wal: reading frame payload: %w", err)
+		}
+		if crc32.ChecksumIEEE(payload) != wantCRC {
+			return nil // corrupt trailing write, stop here
+		}
+		if err := fn(payload); err != nil {
+			return err
+		}
+	}
+}